@@ -0,0 +1,62 @@
+package replicate
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/cassette"
+)
+
+var (
+	defaultTransport = http.DefaultTransport
+
+	recorder   *cassette.Recorder
+	recordPath string
+)
+
+// setUpCassette records or replays every HTTP request the CLI makes
+// if the record or replay flags are set.
+// Clients use http.DefaultClient or http.DefaultTransport, so replacing the transport covers them all.
+func setUpCassette(cmd *cobra.Command) error {
+	http.DefaultTransport = defaultTransport
+	recorder = nil
+
+	recordPath, _ = cmd.Flags().GetString("record")
+	replayPath, _ := cmd.Flags().GetString("replay")
+
+	switch {
+	case recordPath != "":
+		recorder = cassette.NewRecorder(defaultTransport)
+		http.DefaultTransport = recorder
+	case replayPath != "":
+		c, err := cassette.Load(replayPath)
+		if err != nil {
+			return err
+		}
+		http.DefaultTransport = cassette.NewPlayer(c)
+
+		// Tokens are redacted from cassettes, and aren't needed when nothing is sent
+		if err := os.Setenv("REPLICATE_API_TOKEN", cassette.Redacted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveCassette writes what was recorded, even if the command failed
+func saveCassette() error {
+	if recorder == nil {
+		return nil
+	}
+
+	return recorder.Save(recordPath)
+}
+
+func addCassetteFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("record", "", "Record HTTP interactions to a cassette file")
+	cmd.PersistentFlags().String("replay", "", "Respond to HTTP requests from a cassette file instead of sending them")
+	cmd.MarkFlagsMutuallyExclusive("record", "replay")
+}
@@ -0,0 +1,30 @@
+package replicate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/util"
+)
+
+// started is set once a command starts running
+var started bool
+
+// printError writes an error as JSON if the command emits JSON,
+// or as text, followed by the command's usage if asked
+func printError(w io.Writer, cmd *cobra.Command, err error, usage bool) {
+	if cmd.Flags().Changed("json") {
+		if b, jsonErr := json.Marshal(util.NewErrorEnvelope(err)); jsonErr == nil {
+			fmt.Fprintln(w, string(b))
+			return
+		}
+	}
+
+	fmt.Fprintf(w, "Error: %s\n", err)
+	if usage {
+		fmt.Fprintf(w, "\n%s", cmd.UsageString())
+	}
+}
@@ -0,0 +1,472 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/cache"
+	"github.com/replicate/cli/internal/config"
+	"github.com/replicate/cli/internal/history"
+	"github.com/replicate/cli/internal/mockserver"
+	"github.com/replicate/cli/internal/util"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenCase runs commands against a fresh mock server
+// and compares their output to testdata/<name>.golden
+type goldenCase struct {
+	name string
+
+	// commands are run in order, split on spaces
+	commands []string
+
+	tty   bool
+	stdin string
+
+	// options configure the mock server.
+	// Predictions finish as soon as they're created unless this sets steps.
+	options []mockserver.Option
+
+	// interrupt cancels the first command's context after this long, as Ctrl-C does,
+	// so later commands can check what it left behind
+	interrupt time.Duration
+
+	// normalize replaces matches in the output that aren't deterministic
+	normalize map[string]string
+}
+
+var goldenCases = []goldenCase{
+	// account
+	{name: "account_current", commands: []string{"account current"}},
+	{name: "account_current_tty", commands: []string{"account current"}, tty: true},
+
+	// auth
+	{name: "auth_login", commands: []string{"auth login --token-stdin"}, stdin: "test-token\n"},
+	{name: "auth_login_invalid", commands: []string{"auth login --token-stdin"}, stdin: "wrong-token\n", options: []mockserver.Option{mockserver.WithToken("test-token")}},
+
+	// hardware
+	{name: "hardware_list", commands: []string{"hardware list"}},
+	{name: "hardware_list_tty", commands: []string{"hardware list"}, tty: true},
+
+	// model
+	{name: "model_list", commands: []string{"model list"}},
+	{name: "model_show", commands: []string{"model show replicate/hello-world"}},
+	{name: "model_show_tty", commands: []string{"model show replicate/hello-world"}, tty: true},
+	{name: "model_show_not_found", commands: []string{"model show replicate/missing"}},
+	{name: "model_show_not_found_json", commands: []string{"model show replicate/missing --json"}},
+	{name: "model_schema", commands: []string{"model schema stability-ai/sdxl --json"}},
+	{name: "model_schema_tty", commands: []string{"model schema stability-ai/sdxl"}, tty: true},
+	{name: "model_create", commands: []string{"model create test-user/new-model --private --hardware cpu --description Testing"}},
+	{name: "model_create_tty", commands: []string{"model create test-user/new-model --public --hardware cpu"}, tty: true},
+
+	// prediction
+	{name: "prediction_create", commands: []string{"prediction create replicate/hello-world text=world"}},
+	{
+		name:      "prediction_create_wait_cancel_on_interrupt",
+		commands:  []string{"prediction create replicate/hello-world text=world --wait --cancel-on-interrupt", "prediction show mockp00001"},
+		options:   []mockserver.Option{mockserver.WithSteps(100)},
+		interrupt: 200 * time.Millisecond,
+	},
+	{
+		name:      "prediction_create_wait_interrupted",
+		commands:  []string{"prediction create replicate/hello-world text=world --wait", "prediction show mockp00001"},
+		options:   []mockserver.Option{mockserver.WithSteps(100)},
+		interrupt: 200 * time.Millisecond,
+	},
+	{name: "prediction_create_local", commands: []string{"prediction create --local $COG text=world"}},
+	{name: "prediction_create_local_async", commands: []string{"prediction create --local $COG text=async"}},
+	{name: "prediction_create_local_timeout", commands: []string{"prediction create --local $COG text=slow --timeout 100ms"}},
+	{name: "prediction_create_local_interrupted", commands: []string{"prediction create --local $COG text=slow"}, interrupt: 100 * time.Millisecond},
+	{name: "prediction_create_local_pred_ref", commands: []string{"prediction create replicate/hello-world text=world", "prediction create --local $COG text=pred:mockp00001"}},
+	{name: "prediction_create_tty", commands: []string{"prediction create replicate/hello-world text=world"}, tty: true},
+	{name: "prediction_create_json_tty", commands: []string{"prediction create replicate/hello-world text=world --json"}, tty: true},
+	{name: "prediction_create_failed_tty", commands: []string{"prediction create replicate/hello-world text=world fail=true"}, tty: true},
+	{name: "prediction_create_timeout", commands: []string{"prediction create replicate/hello-world text=world --timeout 1500ms"}, options: []mockserver.Option{mockserver.WithSteps(10)}},
+	{name: "prediction_create_failed_json", commands: []string{"prediction create replicate/hello-world text=world fail=true --json"}},
+	{name: "prediction_create_no_wait", commands: []string{"prediction create replicate/hello-world text=world --no-wait"}, options: []mockserver.Option{mockserver.WithSteps(2)}},
+	{name: "prediction_create_no_wait_tty", commands: []string{"prediction create replicate/hello-world text=world --no-wait"}, tty: true, options: []mockserver.Option{mockserver.WithSteps(2)}},
+	{name: "prediction_create_stream", commands: []string{"prediction create meta/llama-2-7b-chat prompt=hello"}},
+	{name: "prediction_create_stream_json", commands: []string{"prediction create meta/llama-2-7b-chat prompt=hello --json"}},
+	{name: "prediction_create_stream_tty", commands: []string{"prediction create meta/llama-2-7b-chat prompt=hello"}, tty: true},
+	{name: "prediction_create_no_stream", commands: []string{"prediction create meta/llama-2-7b-chat prompt=hello --no-stream"}},
+	{name: "prediction_create_stdin", commands: []string{"prediction create replicate/hello-world text={{.text}}"}, stdin: "{\"text\": \"from stdin\"}"},
+	{name: "prediction_create_invalid_model", commands: []string{"prediction create not-a-model"}},
+	{name: "prediction_create_missing_model", commands: []string{"prediction create"}},
+	{name: "prediction_list", commands: []string{"prediction create replicate/hello-world text=a", "prediction create replicate/hello-world text=b", "prediction list"}},
+	{name: "prediction_show", commands: []string{"prediction create replicate/hello-world text=world", "prediction show mockp00001"}},
+	{name: "prediction_show_tty", commands: []string{"prediction create stability-ai/sdxl prompt=corgi num_outputs=2", "prediction show mockp00001"}, tty: true},
+	{name: "prediction_rerun", commands: []string{"prediction create replicate/hello-world text=world", "prediction rerun mockp00001 text=again"}},
+	{name: "prediction_rerun_latest", commands: []string{"prediction create replicate/hello-world text=world", "prediction rerun mockp00001 --latest"}},
+	{name: "prediction_download", commands: []string{"prediction create stability-ai/sdxl prompt=corgi num_outputs=2", "prediction download mockp00001 -o out"}},
+	{name: "prediction_download_failed", commands: []string{"prediction create replicate/hello-world text=world fail=true", "prediction download mockp00001"}},
+	{
+		name:     "prediction_compare",
+		commands: []string{"prediction compare replicate/hello-world meta/llama-2-7b-chat text=world prompt=world"},
+		// The two predictions are created concurrently, so either may be first
+		normalize: map[string]string{
+			`mockp0000[12]`:          "mockp0000N",
+			`2024-01-01T00:00:\d\dZ`: "$TIMESTAMP",
+		},
+	},
+	{name: "prediction_compare_failed", commands: []string{"prediction compare replicate/hello-world replicate/hello-world text=world fail=true"}, normalize: map[string]string{`mockp0000[12]`: "mockp0000N", `2024-01-01T00:00:\d\dZ`: "$TIMESTAMP"}},
+	{name: "prediction_compare_input_json", commands: []string{`prediction compare replicate/hello-world stability-ai/sdxl --input-json {"text":"world","prompt":"world"}`}, normalize: map[string]string{`mockp0000[12]`: "mockp0000N", `2024-01-01T00:00:\d\dZ`: "$TIMESTAMP"}},
+	{name: "prediction_top", commands: []string{"prediction top"}},
+	{
+		name: "prediction_record_replay",
+		commands: []string{
+			"prediction create meta/llama-2-7b-chat prompt=hello --record cassette.yaml",
+			"prediction create meta/llama-2-7b-chat prompt=hello --replay cassette.yaml",
+			"prediction create meta/llama-2-7b-chat prompt=goodbye --replay cassette.yaml",
+		},
+	},
+
+	// completion
+	{name: "complete_models", commands: []string{"run replicate/hello-world text=world", "__complete run replicate/"}},
+	{name: "complete_versions", commands: []string{"__complete model show replicate/hello-world:"}},
+	{name: "complete_inputs", commands: []string{"__complete run stability-ai/sdxl prompt=corgi s"}},
+	{name: "complete_inputs_after_run", commands: []string{"run replicate/hello-world text=world", "__complete prediction create replicate/hello-world t"}},
+	{name: "complete_predictions", commands: []string{"run replicate/hello-world text=world", "__complete prediction show mock"}},
+	{name: "complete_trainings", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data", "__complete training show mock"}},
+	{name: "complete_deployments", commands: []string{"__complete deployments run test"}},
+	{name: "complete_hardware", commands: []string{"__complete deployments create text-to-image --hardware gpu"}},
+
+	// history
+	{name: "history", commands: []string{"run replicate/hello-world text=world", "run replicate/hello-world text=corgi fail=true", "training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data", "history --status failed", "history corgi --limit 1"}},
+	{name: "history_show_tty", commands: []string{"run stability-ai/sdxl prompt=corgi num_outputs=2", "history show 1"}, tty: true},
+	{name: "history_show_missing", commands: []string{"history show 1"}},
+	{name: "history_rerun", commands: []string{"run stability-ai/sdxl prompt=corgi", "prediction rerun 1 seed=42", "prediction download 2 -o out", "history show 2"}},
+	{name: "history_stream", commands: []string{"run meta/llama-2-7b-chat prompt=hello", "history --kind prediction"}},
+	{name: "history_training_show", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data", "training show 1", "prediction show 1"}},
+
+	// usage
+	{
+		name: "usage",
+		commands: []string{
+			"run replicate/hello-world text=a",
+			"run replicate/hello-world text=b fail=true",
+			"deployments run test-user/hello-world text=c",
+			"run stability-ai/sdxl prompt=corgi",
+			"usage --since 2024-01-01 --until 2024-01-02 --group-by model,deployment,hardware",
+		},
+	},
+	{name: "usage_csv", commands: []string{"run replicate/hello-world text=a", "deployments run test-user/hello-world text=b", "usage --since 2024-01-01 --group-by hardware --csv"}},
+	{name: "usage_tty", commands: []string{"run replicate/hello-world text=a", "deployments run test-user/hello-world text=b", "usage --since 2024-01-01 --until 2024-01-02"}, tty: true},
+	{name: "usage_invalid_group", commands: []string{"usage --group-by owner"}},
+
+	// bench
+	{name: "bench", commands: []string{"bench replicate/hello-world text=world -n 3"}, normalize: benchNormalize},
+	{name: "bench_stream", commands: []string{"bench meta/llama-2-7b-chat prompt=hi -n 2"}, normalize: benchNormalize},
+	{name: "bench_failed", commands: []string{"bench replicate/hello-world text=world fail=true -n 2"}, normalize: benchNormalize},
+	{name: "bench_deployment", commands: []string{"bench test-user/hello-world --deployment text=world -n 2 --cold-start 1s"}, normalize: benchNormalize},
+	{name: "bench_tty", commands: []string{"bench replicate/hello-world text=world -n 2"}, tty: true, normalize: benchNormalize},
+	{name: "bench_invalid_count", commands: []string{"bench replicate/hello-world text=world -n 0"}},
+	{
+		name:      "bench_interrupted",
+		commands:  []string{"bench replicate/hello-world text=world -n 3 --poll-interval 50ms", "prediction show mockp00001", "history"},
+		options:   []mockserver.Option{mockserver.WithSteps(100)},
+		interrupt: 200 * time.Millisecond,
+		// How far the prediction got depends on how often it was polled
+		normalize: map[string]string{
+			`"logs": ".*"`:         `"logs": $LOGS`,
+			`"completed_at": ".*"`: `"completed_at": $TIMESTAMP`,
+		},
+	},
+
+	// training
+	{name: "training_create", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data"}},
+	{name: "training_create_coerced", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=123 epochs=2"}},
+	{name: "training_create_input_json", commands: []string{`training create replicate/hello-world --destination test-user/hello-world-fine-tuned --input-json {"text":"data"} epochs=3`}},
+	{name: "training_create_delete_uploads_without_wait", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data --delete-uploads"}},
+	{name: "training_create_tty", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data"}, tty: true},
+	{
+		name:      "training_create_wait_cancel_on_interrupt",
+		commands:  []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data --wait --cancel-on-interrupt", "training show mockt00001"},
+		options:   []mockserver.Option{mockserver.WithSteps(100)},
+		interrupt: 200 * time.Millisecond,
+	},
+	{name: "training_create_wait_tty", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data --wait"}, tty: true, options: []mockserver.Option{mockserver.WithSteps(1)}},
+	{name: "training_create_wait_failed", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data fail=true --wait"}, options: []mockserver.Option{mockserver.WithSteps(1)}},
+	{name: "training_create_timeout_without_wait", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data --timeout 1m"}},
+	{name: "training_create_missing_destination", commands: []string{"training create replicate/hello-world --destination test-user/missing text=data"}},
+	{name: "training_list", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data", "training list"}},
+	{name: "training_list_json_tty", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data", "training list --json"}, tty: true},
+	{name: "training_show", commands: []string{"training create replicate/hello-world --destination test-user/hello-world-fine-tuned text=data", "training show mockt00001"}},
+
+	// deployment
+	{name: "deployment_list", commands: []string{"deployments list"}},
+	{name: "deployment_show", commands: []string{"deployments show test-user/hello-world"}},
+	{name: "deployment_show_tty", commands: []string{"deployments show test-user/hello-world"}, tty: true},
+	{name: "deployment_schema", commands: []string{"deployments schema test-user/hello-world --json"}},
+	{name: "deployment_schema_tty", commands: []string{"deployments schema test-user/hello-world"}, tty: true},
+	{name: "deployment_create", commands: []string{"deployments create text-to-image --model stability-ai/sdxl --hardware gpu-t4 --min-instances 1 --max-instances 2"}},
+	{name: "deployment_create_tty", commands: []string{"deployments create text-to-image --model stability-ai/sdxl --hardware gpu-t4"}, tty: true},
+	{name: "deployment_update", commands: []string{"deployments update test-user/hello-world --max-instances 3"}},
+	{name: "deployment_update_tty", commands: []string{"deployments update test-user/hello-world --hardware gpu-t4"}, tty: true},
+	{name: "deployment_run", commands: []string{"deployments run test-user/hello-world text=world"}},
+	{name: "deployment_run_tty", commands: []string{"deployments run test-user/hello-world text=world"}, tty: true},
+}
+
+func TestGolden(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	t.Cleanup(func() { util.SetTTY(false) })
+
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	// Cases run in temporary directories
+	testdata, err := filepath.Abs("testdata")
+	assert.NoError(t, err)
+
+	for _, tc := range goldenCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := runGoldenCase(t, tc)
+
+			path := filepath.Join(testdata, tc.name+".golden")
+			if *update {
+				assert.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if assert.NoError(t, err, "run go test ./cmd/replicate -update to create golden files") {
+				assert.Equal(t, string(want), got)
+			}
+		})
+	}
+}
+
+// runGoldenCase runs a case's commands and returns their combined output
+func runGoldenCase(t *testing.T, tc goldenCase) string {
+	options := append([]mockserver.Option{mockserver.WithSteps(0)}, tc.options...)
+	server := httptest.NewServer(mockserver.New(options...))
+	t.Cleanup(server.Close)
+
+	cogServer := httptest.NewServer(http.HandlerFunc(serveCog))
+	t.Cleanup(cogServer.Close)
+
+	dir := t.TempDir()
+	t.Setenv("REPLICATE_BASE_URL", server.URL)
+	t.Setenv("REPLICATE_API_TOKEN", "test-token")
+	t.Setenv("REPLICATE_IMAGE_PROTOCOL", "none")
+	t.Setenv("REPLICATE_LOCAL_COG_URL", "")
+
+	configFilePath := config.ConfigFilePath
+	config.ConfigFilePath = filepath.Join(dir, "config", "hosts")
+	t.Cleanup(func() { config.ConfigFilePath = configFilePath })
+
+	cacheDir := cache.Dir
+	cache.Dir = filepath.Join(dir, "cache")
+	t.Cleanup(func() { cache.Dir = cacheDir })
+
+	historyPath := history.Path
+	history.Path = filepath.Join(dir, "state", "history.jsonl")
+	t.Cleanup(func() { history.Path = historyPath })
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	util.SetTTY(tc.tty)
+
+	var b strings.Builder
+	for i, command := range tc.commands {
+		args := strings.Fields(strings.ReplaceAll(command, "$COG", cogServer.URL))
+		var interrupt time.Duration
+		if i == 0 {
+			interrupt = tc.interrupt
+		}
+		stdout, stderr, code := runCommand(t, args, tc.stdin, interrupt)
+
+		fmt.Fprintf(&b, "$ replicate %s\n", command)
+		writeSection(&b, "stdout", stdout)
+		writeSection(&b, "stderr", stderr)
+		if code != 0 {
+			writeSection(&b, "exit code", strconv.Itoa(code))
+		}
+	}
+
+	got := strings.ReplaceAll(b.String(), server.URL, "$SERVER")
+	got = strings.ReplaceAll(got, cogServer.URL, "$COG")
+	got = regexp.MustCompile(`local-[0-9a-f]{16}`).ReplaceAllLiteralString(got, "local-$ID")
+	got = strings.ReplaceAll(got, dir, "$TMP")
+	for pattern, replacement := range tc.normalize {
+		got = regexp.MustCompile(pattern).ReplaceAllLiteralString(got, replacement)
+	}
+
+	return got
+}
+
+// serveCog is a local Cog server for commands run with --local $COG.
+// It greets its text input, runs until it's canceled if the text is "slow",
+// or answers as if it were running asynchronously if the text is "async".
+func serveCog(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/openapi.json":
+		_, _ = w.Write([]byte(`{"components":{"schemas":{"Input":{"type":"object","properties":{"text":{"type":"string"}}},"Output":{"type":"string"}}}}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/predictions":
+		var body struct {
+			ID    string                 `json:"id"`
+			Input map[string]interface{} `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		text, _ := body.Input["text"].(string)
+		if text == "slow" {
+			<-r.Context().Done()
+			return
+		}
+
+		if text == "async" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": body.ID, "input": body.Input, "status": "starting"})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     body.ID,
+			"input":  body.Input,
+			"output": "hello " + text,
+			"status": "succeeded",
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// benchNormalize replaces the latencies that bench measures itself
+var benchNormalize = map[string]string{
+	`"latency": \{[^}]*\}`:                   `"latency": $STATS`,
+	`"time_to_first_token": \{[^}]*\}`:       `"time_to_first_token": $STATS`,
+	`"latency": [0-9][0-9.e+-]*`:             `"latency": $SECONDS`,
+	`"time_to_first_token": [0-9][0-9.e+-]*`: `"time_to_first_token": $SECONDS`,
+	`"wall_time": [0-9][0-9.e+-]*`:           `"wall_time": $SECONDS`,
+	` in [0-9.]+s\n`:                         " in $SECONDS\n",
+	`Latency .*`:                             "Latency $STATS",
+}
+
+// runCommand executes the root command with args,
+// capturing what it writes to stdout and stderr, and its exit code.
+// If interrupt is set, the command's context is canceled after that long.
+func runCommand(t *testing.T, args []string, stdin string, interrupt time.Duration) (string, string, int) {
+	dir := t.TempDir()
+
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	assert.NoError(t, err)
+	defer stdout.Close()
+
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	assert.NoError(t, err)
+	defer stderr.Close()
+
+	// Piped input is only read from a pipe, so stdin is a pipe if there's any
+	var input *os.File
+	if stdin != "" {
+		r, w, err := os.Pipe()
+		assert.NoError(t, err)
+		_, _ = w.WriteString(stdin)
+		w.Close()
+		input = r
+	} else {
+		input, err = os.Open(os.DevNull)
+		assert.NoError(t, err)
+	}
+	defer input.Close()
+
+	origStdout, origStderr, origStdin := os.Stdout, os.Stderr, os.Stdin
+	os.Stdout, os.Stderr, os.Stdin = stdout, stderr, input
+	defer func() {
+		os.Stdout, os.Stderr, os.Stdin = origStdout, origStderr, origStdin
+	}()
+
+	// Streams stay open until their client disconnects
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if interrupt > 0 {
+		timer := time.AfterFunc(interrupt, cancel)
+		defer timer.Stop()
+	}
+
+	// History records the command line
+	origArgs := os.Args
+	os.Args = append([]string{"replicate"}, args...)
+	defer func() { os.Args = origArgs }()
+
+	resetCommands(rootCmd, ctx)
+	rootCmd.SetArgs(args)
+	code := run()
+
+	out, err := os.ReadFile(stdout.Name())
+	assert.NoError(t, err)
+	errOut, err := os.ReadFile(stderr.Name())
+	assert.NoError(t, err)
+
+	return string(out), string(errOut), code
+}
+
+// resetCommands sets the context of every command and every flag back to its default,
+// since cobra keeps both between executions
+func resetCommands(cmd *cobra.Command, ctx context.Context) {
+	cmd.SetContext(ctx)
+
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if defaults := strings.Trim(f.DefValue, "[]"); defaults != "" {
+				values = strings.Split(defaults, ",")
+			}
+			if f.Value.Type() == "stringSlice" {
+				// Setting a string slice appends once it's been set, even after Replace,
+				// so it's swapped for a new one
+				fresh := pflag.NewFlagSet(f.Name, pflag.ContinueOnError)
+				fresh.StringSlice(f.Name, values, f.Usage)
+				f.Value = fresh.Lookup(f.Name).Value
+			} else {
+				_ = v.Replace(values)
+			}
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetCommands(c, ctx)
+	}
+}
+
+func writeSection(b *strings.Builder, name string, content string) {
+	if content == "" {
+		return
+	}
+
+	fmt.Fprintf(b, "-- %s --\n%s", name, content)
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+}
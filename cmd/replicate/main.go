@@ -1,6 +1,8 @@
 package replicate
 
 import (
+	"context"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -10,28 +12,73 @@ import (
 	"github.com/replicate/cli/internal/cmd/account"
 	"github.com/replicate/cli/internal/cmd/auth"
 	"github.com/replicate/cli/internal/cmd/deployment"
+	"github.com/replicate/cli/internal/cmd/dev"
+	"github.com/replicate/cli/internal/cmd/file"
 	"github.com/replicate/cli/internal/cmd/hardware"
+	"github.com/replicate/cli/internal/cmd/history"
 	"github.com/replicate/cli/internal/cmd/model"
 	"github.com/replicate/cli/internal/cmd/prediction"
 	"github.com/replicate/cli/internal/cmd/training"
+	"github.com/replicate/cli/internal/cmd/webhook"
+	"github.com/replicate/cli/internal/cmd/workflow"
+	"github.com/replicate/cli/internal/util"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:     "replicate",
 	Version: internal.Version(),
+	// Errors and usage are printed by run, as JSON for commands that emit JSON
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		started = true
+		return setUpCassette(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := util.NotifyInterrupt(context.Background())
+	rootCmd.SetContext(ctx)
+
+	code := run()
+	stop()
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// run executes the root command, prints any error,
+// and returns an exit code for the kind of error, as documented in the README
+func run() int {
+	started = false
+	cmd, err := rootCmd.ExecuteC()
 	if err != nil {
-		os.Exit(1)
+		// Errors from before a command started running are about its arguments or flags
+		usage := !started
+		if usage {
+			err = util.InvalidInput(err)
+		}
+		printError(os.Stderr, cmd, err, usage)
+	}
+	if saveErr := saveCassette(); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", saveErr)
+		if err == nil {
+			err = saveErr
+		}
 	}
+	if err != nil {
+		return util.Kind(err).ExitCode()
+	}
+
+	return 0
 }
 
 func init() {
+	addCassetteFlags(rootCmd)
+
 	rootCmd.AddGroup(&cobra.Group{
 		ID:    "core",
 		Title: "Core commands:",
@@ -44,7 +91,14 @@ func init() {
 		training.RootCmd,
 		deployment.RootCmd,
 		hardware.RootCmd,
+		history.RootCmd,
+		file.RootCmd,
+		webhook.RootCmd,
+		workflow.RootCmd,
+		dev.RootCmd,
 		cmd.ScaffoldCmd,
+		cmd.UsageCmd,
+		cmd.BenchCmd,
 	} {
 		rootCmd.AddCommand(cmd)
 		cmd.GroupID = "core"
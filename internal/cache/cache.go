@@ -0,0 +1,141 @@
+// Package cache keeps model versions on disk,
+// so shell completion can offer inputs from their schemas without waiting for the API.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+
+	"github.com/replicate/cli/internal/config"
+)
+
+// LatestTTL is how long a model's latest version is cached for.
+// Versions themselves don't change, so they're cached indefinitely.
+const LatestTTL = 24 * time.Hour
+
+// Dir is where the cache is kept
+var Dir string
+
+func init() {
+	// Look for the cache in the XDG_CACHE_HOME directory
+	if cacheDir, exists := os.LookupEnv("XDG_CACHE_HOME"); exists {
+		Dir = filepath.Join(cacheDir, "replicate")
+	} else if cacheDir, err := os.UserCacheDir(); err == nil {
+		Dir = filepath.Join(cacheDir, "replicate")
+	}
+}
+
+// SaveVersion caches a version of a model,
+// and records it as the model's latest version if latest is true
+func SaveVersion(owner string, name string, version *replicate.ModelVersion, latest bool) error {
+	if Dir == "" || version == nil || version.ID == "" {
+		return nil
+	}
+
+	dir := modelDir(owner, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(version)
+	if err != nil {
+		return fmt.Errorf("failed to marshal version: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, version.ID+".json"), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	if latest {
+		if err := os.WriteFile(filepath.Join(dir, "latest"), []byte(version.ID), 0o644); err != nil {
+			return fmt.Errorf("failed to write cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// LoadVersion returns a cached version of a model,
+// or its latest version if versionID is empty and it was cached recently.
+// It returns nil if there isn't one.
+func LoadVersion(owner string, name string, versionID string) *replicate.ModelVersion {
+	if Dir == "" {
+		return nil
+	}
+
+	dir := modelDir(owner, name)
+
+	if versionID == "" {
+		latest := filepath.Join(dir, "latest")
+		info, err := os.Stat(latest)
+		if err != nil || time.Since(info.ModTime()) > LatestTTL {
+			return nil
+		}
+
+		data, err := os.ReadFile(latest)
+		if err != nil {
+			return nil
+		}
+		versionID = strings.TrimSpace(string(data))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.Base(versionID)+".json"))
+	if err != nil {
+		return nil
+	}
+
+	var version replicate.ModelVersion
+	if err := json.Unmarshal(data, &version); err != nil {
+		return nil
+	}
+
+	return &version
+}
+
+// Models returns the names of models with cached versions, like owner/name
+func Models() []string {
+	if Dir == "" {
+		return nil
+	}
+
+	owners, err := os.ReadDir(modelsDir())
+	if err != nil {
+		return nil
+	}
+
+	models := []string{}
+	for _, owner := range owners {
+		names, err := os.ReadDir(filepath.Join(modelsDir(), owner.Name()))
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			models = append(models, owner.Name()+"/"+name.Name())
+		}
+	}
+
+	return models
+}
+
+// modelDir returns the directory for a model's versions
+func modelDir(owner string, name string) string {
+	return filepath.Join(modelsDir(), filepath.Base(owner), filepath.Base(name))
+}
+
+// modelsDir returns the directory for models,
+// which is separate for each API host, since they may have different models
+func modelsDir() string {
+	host := config.GetAPIBaseURL()
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.ReplaceAll(host, ":", "_")
+
+	return filepath.Join(Dir, host, "models")
+}
@@ -0,0 +1,52 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/replicate/replicate-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/cache"
+)
+
+func TestVersions(t *testing.T) {
+	cache.Dir = t.TempDir()
+	t.Setenv("REPLICATE_BASE_URL", "http://127.0.0.1:8787")
+
+	assert.Nil(t, cache.LoadVersion("replicate", "hello-world", ""))
+
+	v1 := &replicate.ModelVersion{ID: "v1", OpenAPISchema: map[string]interface{}{"openapi": "3.0.2"}}
+	v2 := &replicate.ModelVersion{ID: "v2"}
+	assert.NoError(t, cache.SaveVersion("replicate", "hello-world", v1, true))
+	assert.NoError(t, cache.SaveVersion("replicate", "hello-world", v2, false))
+
+	latest := cache.LoadVersion("replicate", "hello-world", "")
+	assert.Equal(t, "v1", latest.ID)
+	assert.Equal(t, v1.OpenAPISchema, latest.OpenAPISchema)
+	assert.Equal(t, "v2", cache.LoadVersion("replicate", "hello-world", "v2").ID)
+	assert.Nil(t, cache.LoadVersion("replicate", "hello-world", "v3"))
+	assert.Equal(t, []string{"replicate/hello-world"}, cache.Models())
+
+	// Each API host has its own cache
+	t.Setenv("REPLICATE_BASE_URL", "https://api.replicate.com/v1/")
+	assert.Nil(t, cache.LoadVersion("replicate", "hello-world", "v2"))
+	assert.Empty(t, cache.Models())
+}
+
+func TestLatestVersionExpires(t *testing.T) {
+	cache.Dir = t.TempDir()
+	t.Setenv("REPLICATE_BASE_URL", "http://127.0.0.1:8787")
+
+	version := &replicate.ModelVersion{ID: "v1"}
+	assert.NoError(t, cache.SaveVersion("replicate", "hello-world", version, true))
+
+	latest := filepath.Join(cache.Dir, "127.0.0.1_8787", "models", "replicate", "hello-world", "latest")
+	old := time.Now().Add(-cache.LatestTTL - time.Minute)
+	assert.NoError(t, os.Chtimes(latest, old, old))
+
+	assert.Nil(t, cache.LoadVersion("replicate", "hello-world", ""))
+	assert.Equal(t, "v1", cache.LoadVersion("replicate", "hello-world", "v1").ID)
+}
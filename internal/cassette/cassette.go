@@ -0,0 +1,162 @@
+// Package cassette records HTTP interactions to a file and replays them,
+// for reproducing bug reports and making deterministic demos without a network.
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces API tokens and webhook signing secrets in recorded interactions
+const Redacted = "REDACTED"
+
+// Version is the version of the cassette file format
+const Version = 1
+
+// Cassette is a list of recorded HTTP interactions
+type Cassette struct {
+	Version      int            `yaml:"version"`
+	Interactions []*Interaction `yaml:"interactions"`
+}
+
+// Interaction is a request and the response it got
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+type Request struct {
+	Method  string      `yaml:"method"`
+	URL     string      `yaml:"url"`
+	Headers http.Header `yaml:"headers,omitempty"`
+	Body    Body        `yaml:"body,omitempty"`
+}
+
+type Response struct {
+	Status  int         `yaml:"status"`
+	Headers http.Header `yaml:"headers,omitempty"`
+	Body    Body        `yaml:"body,omitempty"`
+}
+
+// Body is stored as text, or as base64 if it isn't valid UTF-8
+type Body []byte
+
+type encodedBody struct {
+	Encoding string `yaml:"encoding"`
+	Data     string `yaml:"data"`
+}
+
+func (b Body) MarshalYAML() (interface{}, error) {
+	if utf8.Valid(b) {
+		return string(b), nil
+	}
+	return encodedBody{Encoding: "base64", Data: base64.StdEncoding.EncodeToString(b)}, nil
+}
+
+func (b *Body) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*b = Body(node.Value)
+		return nil
+	}
+
+	var encoded encodedBody
+	if err := node.Decode(&encoded); err != nil {
+		return err
+	}
+	if encoded.Encoding != "base64" {
+		return fmt.Errorf("unknown body encoding %q", encoded.Encoding)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded.Data)
+	if err != nil {
+		return fmt.Errorf("invalid base64 body: %w", err)
+	}
+	*b = data
+	return nil
+}
+
+func (b Body) IsZero() bool {
+	return len(b) == 0
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("unsupported cassette version %d", c.Version)
+	}
+
+	return &c, nil
+}
+
+// Save writes a cassette file
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// matches reports whether an interaction was for a request
+// with the same method, path, query and body
+func (i *Interaction) matches(method string, path string, contentType string, body []byte) bool {
+	if i.Request.Method != method || requestPath(i.Request.URL) != path {
+		return false
+	}
+
+	return bytes.Equal(
+		normalizeBody(i.Request.Headers.Get("Content-Type"), i.Request.Body),
+		normalizeBody(contentType, body),
+	)
+}
+
+// normalizeBody makes equivalent bodies equal,
+// by compacting JSON and replacing multipart boundaries, which are random
+func normalizeBody(contentType string, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body
+	}
+
+	switch {
+	case mediaType == "application/json":
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return body
+		}
+		normalized, err := json.Marshal(v)
+		if err != nil {
+			return body
+		}
+		return normalized
+	case strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "":
+		return bytes.ReplaceAll(body, []byte(params["boundary"]), []byte("BOUNDARY"))
+	}
+
+	return body
+}
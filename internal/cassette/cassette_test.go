@@ -0,0 +1,143 @@
+package cassette_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/replicate/replicate-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/cassette"
+	"github.com/replicate/cli/internal/mockserver"
+)
+
+func newClient(t *testing.T, baseURL string, transport http.RoundTripper) *replicate.Client {
+	r8, err := replicate.NewClient(
+		replicate.WithBaseURL(baseURL),
+		replicate.WithToken("secret-token"),
+		replicate.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	assert.NoError(t, err)
+	return r8
+}
+
+// session makes requests a command might, and returns what it got
+func session(t *testing.T, r8 *replicate.Client, transport http.RoundTripper) (*replicate.Prediction, []byte, *replicate.File) {
+	ctx := context.Background()
+
+	prediction, err := r8.CreatePredictionWithModel(ctx, "stability-ai", "sdxl", replicate.PredictionInput{"prompt": "a corgi"}, nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, r8.Wait(ctx, prediction))
+
+	resp, err := (&http.Client{Transport: transport}).Get(prediction.Output.([]interface{})[0].(string))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	image, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	file, err := r8.CreateFileFromBytes(ctx, []byte("hello"), &replicate.CreateFileOptions{Filename: "hello.txt"})
+	assert.NoError(t, err)
+
+	return prediction, image, file
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(mockserver.New(mockserver.WithSteps(1)))
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+	recorder := cassette.NewRecorder(http.DefaultTransport)
+	recorded, recordedImage, recordedFile := session(t, newClient(t, server.URL, recorder), recorder)
+	assert.NoError(t, recorder.Save(path))
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+	assert.Contains(t, string(data), "Bearer "+cassette.Redacted)
+	assert.Contains(t, string(data), "encoding: base64")
+
+	c, err := cassette.Load(path)
+	assert.NoError(t, err)
+
+	// The server is closed, so everything comes from the cassette
+	player := cassette.NewPlayer(c)
+	replayed, replayedImage, replayedFile := session(t, newClient(t, server.URL, player), player)
+	assert.Equal(t, recorded, replayed)
+	assert.Equal(t, recordedImage, replayedImage)
+	assert.Equal(t, recordedFile, replayedFile)
+
+	_, err = newClient(t, server.URL, player).CreatePredictionWithModel(context.Background(), "stability-ai", "sdxl", replicate.PredictionInput{"prompt": "a cat"}, nil, false)
+	assert.ErrorContains(t, err, "no recorded response for POST /models/stability-ai/sdxl/predictions")
+}
+
+func TestRecordRedactsWebhookSecret(t *testing.T) {
+	server := httptest.NewServer(mockserver.New())
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+	recorder := cassette.NewRecorder(http.DefaultTransport)
+	secret, err := newClient(t, server.URL, recorder).GetDefaultWebhookSecret(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, mockserver.WebhookSecret, secret.Key)
+	assert.NoError(t, recorder.Save(path))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), mockserver.WebhookSecret)
+	assert.Contains(t, string(data), "whsec_"+cassette.Redacted)
+}
+
+func TestReplayStream(t *testing.T) {
+	c, err := cassette.Load(writeCassette(t, `version: 1
+interactions:
+  - request:
+      method: GET
+      url: http://example.com/stream/abc
+    response:
+      status: 200
+      headers:
+        Content-Type: [text/event-stream]
+      body: |+
+        event: output
+        id: 1
+        data: hello
+
+        event: done
+        id: 2
+        data: {}
+
+`))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/stream/abc", nil)
+	resp, err := cassette.NewPlayer(c).RoundTrip(req)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, err := resp.Body.Read(buf)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(buf[:n]), "event: done\nid: 2\ndata: {}\n\n"))
+
+	// Like a server, the stream stays open until the request is canceled
+	cancel()
+	_, err = resp.Body.Read(buf)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	_, err := cassette.Load(writeCassette(t, "version: 2\ninteractions: []\n"))
+	assert.ErrorContains(t, err, "unsupported cassette version 2")
+}
+
+func writeCassette(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
@@ -0,0 +1,272 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// webhookSecretPattern matches webhook signing secrets, like the one returned by GET /webhooks/default/secret
+var webhookSecretPattern = regexp.MustCompile(`whsec_[A-Za-z0-9+/=]+`)
+
+// Recorder is an http.RoundTripper that records the interactions of another
+type Recorder struct {
+	base http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	tokens   map[string]bool
+}
+
+// NewRecorder returns a recorder that sends requests with base
+func NewRecorder(base http.RoundTripper) *Recorder {
+	return &Recorder{
+		base:     base,
+		cassette: Cassette{Version: Version},
+		tokens:   map[string]bool{},
+	}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, req, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header.Clone(),
+			Body:    body,
+		},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: resp.Header.Clone(),
+		},
+	}
+
+	r.mu.Lock()
+	if _, token, ok := strings.Cut(req.Header.Get("Authorization"), " "); ok && token != "" {
+		r.tokens[token] = true
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	// Bodies are recorded as they're read, so streams are recorded up to where the client stopped
+	resp.Body = &recordingBody{ReadCloser: resp.Body, recorder: r, interaction: interaction}
+
+	return resp, nil
+}
+
+// Save writes what's been recorded so far to a cassette file,
+// with API tokens and webhook signing secrets redacted
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := Cassette{Version: Version}
+	for _, interaction := range r.cassette.Interactions {
+		c.Interactions = append(c.Interactions, r.redact(interaction))
+	}
+
+	return c.Save(path)
+}
+
+// redact returns a copy of an interaction without API tokens or webhook signing secrets
+func (r *Recorder) redact(interaction *Interaction) *Interaction {
+	replace := func(s string) string {
+		for token := range r.tokens {
+			s = strings.ReplaceAll(s, token, Redacted)
+		}
+		return webhookSecretPattern.ReplaceAllString(s, "whsec_"+Redacted)
+	}
+	headers := func(h http.Header) http.Header {
+		redacted := http.Header{}
+		for key, values := range h {
+			for _, value := range values {
+				redacted.Add(key, replace(value))
+			}
+		}
+		return redacted
+	}
+
+	return &Interaction{
+		Request: Request{
+			Method:  interaction.Request.Method,
+			URL:     replace(interaction.Request.URL),
+			Headers: headers(interaction.Request.Headers),
+			Body:    Body(replace(string(interaction.Request.Body))),
+		},
+		Response: Response{
+			Status:  interaction.Response.Status,
+			Headers: headers(interaction.Response.Headers),
+			Body:    Body(replace(string(interaction.Response.Body))),
+		},
+	}
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	recorder    *Recorder
+	interaction *Interaction
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.recorder.mu.Lock()
+		b.interaction.Response.Body = append(b.interaction.Response.Body, p[:n]...)
+		b.recorder.mu.Unlock()
+	}
+	return n, err
+}
+
+// Player is an http.RoundTripper that responds with recorded interactions,
+// without sending any requests.
+//
+// Requests match interactions with the same method, path, query and body.
+// Each interaction is used once, in order, except the last match for a request,
+// which is reused, so polling a finished prediction keeps getting the same response.
+type Player struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewPlayer returns a player for a cassette
+func NewPlayer(c *Cassette) *Player {
+	return &Player{cassette: c, used: make([]bool, len(c.Interactions))}
+}
+
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, req, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := p.find(req.Method, req.URL.RequestURI(), req.Header.Get("Content-Type"), body)
+	if interaction == nil {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL.RequestURI())
+	}
+
+	recorded := interaction.Response
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+
+	// Streams stay open after their events, like they do on the server
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/event-stream" {
+		resp.ContentLength = -1
+		resp.Body = newStreamBody(req.Context(), recorded.Body)
+	} else {
+		resp.Body = io.NopCloser(bytes.NewReader(recorded.Body))
+	}
+
+	return resp, nil
+}
+
+func (p *Player) find(method string, path string, contentType string, body []byte) *Interaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	last := -1
+	for i, interaction := range p.cassette.Interactions {
+		if !interaction.matches(method, path, contentType, body) {
+			continue
+		}
+		if !p.used[i] {
+			p.used[i] = true
+			return interaction
+		}
+		last = i
+	}
+
+	if last < 0 {
+		return nil
+	}
+	return p.cassette.Interactions[last]
+}
+
+// streamBody reads recorded events, then blocks until it's closed or its request is canceled
+type streamBody struct {
+	reader *bytes.Reader
+	ctx    context.Context
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newStreamBody(ctx context.Context, data []byte) *streamBody {
+	return &streamBody{reader: bytes.NewReader(data), ctx: ctx, closed: make(chan struct{})}
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	if b.reader.Len() > 0 {
+		return b.reader.Read(p)
+	}
+
+	select {
+	case <-b.ctx.Done():
+		return 0, b.ctx.Err()
+	case <-b.closed:
+		return 0, io.EOF
+	}
+}
+
+func (b *streamBody) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
+// readRequestBody reads a request's body,
+// and returns a copy of the request that can be sent with it
+func readRequestBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return body, req, nil
+}
+
+// requestPath returns the path and query of a URL
+func requestPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.RequestURI()
+}
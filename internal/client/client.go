@@ -3,12 +3,15 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 
 	"github.com/replicate/replicate-go"
 
 	"github.com/replicate/cli/internal"
 	"github.com/replicate/cli/internal/config"
+	"github.com/replicate/cli/internal/util"
 )
 
 func NewClient(opts ...replicate.ClientOption) (*replicate.Client, error) {
@@ -22,7 +25,7 @@ func NewClient(opts ...replicate.ClientOption) (*replicate.Client, error) {
 	// Validate token when connecting to api.replicate.com.
 	// Alternate API hosts proxying Replicate may not require a token.
 	if token == "" && baseURL == config.DefaultBaseURL {
-		return nil, fmt.Errorf("please authenticate with `replicate auth login`")
+		return nil, util.AuthErrorf("please authenticate with `replicate auth login`")
 	}
 
 	return NewClientWithAPIToken(token, opts...)
@@ -46,6 +49,44 @@ func NewClientWithAPIToken(token string, opts ...replicate.ClientOption) (*repli
 	return r8, nil
 }
 
+// NewHTTPClient returns an HTTP client that authenticates requests to the API host,
+// for downloading resources like uploaded files
+func NewHTTPClient() (*http.Client, error) {
+	token, err := getToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	baseURL, err := url.Parse(getBaseURL())
+	if err != nil {
+		return nil, fmt.Errorf("invalid API base URL: %w", err)
+	}
+
+	return &http.Client{
+		Transport: &tokenTransport{
+			token: token,
+			host:  baseURL.Host,
+			base:  http.DefaultTransport,
+		},
+	}, nil
+}
+
+// tokenTransport adds the API token to requests to the API host
+type tokenTransport struct {
+	token string
+	host  string
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.token != "" && req.URL.Host == t.host {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
 func VerifyToken(ctx context.Context, token string) (bool, error) {
 	r8, err := NewClientWithAPIToken(token)
 	if err != nil {
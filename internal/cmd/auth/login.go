@@ -10,6 +10,7 @@ import (
 
 	"github.com/replicate/cli/internal/client"
 	"github.com/replicate/cli/internal/config"
+	"github.com/replicate/cli/internal/util"
 )
 
 // loginCmd represents the login command
@@ -40,10 +41,10 @@ You can find your Replicate API token at https://replicate.com/account`,
 				return fmt.Errorf("failed to read token from stdin: %w", err)
 			}
 			if token == "" {
-				return fmt.Errorf("no token provided (empty string)")
+				return util.InvalidInputf("no token provided (empty string)")
 			}
 		} else {
-			return fmt.Errorf("token must be passed to stdin with --token-stdin flag")
+			return util.InvalidInputf("token must be passed to stdin with --token-stdin flag")
 		}
 		token = strings.TrimSpace(token)
 
@@ -52,7 +53,7 @@ You can find your Replicate API token at https://replicate.com/account`,
 			return fmt.Errorf("error verifying token: %w", err)
 		}
 		if !ok {
-			return fmt.Errorf("invalid token")
+			return util.AuthErrorf("invalid token")
 		}
 
 		if err := config.SetAPIToken(token); err != nil {
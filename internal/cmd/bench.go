@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mattn/go-isatty"
+	"github.com/replicate/replicate-go"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/cmd/deployment"
+	"github.com/replicate/cli/internal/cmd/prediction"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/util"
+)
+
+var BenchCmd = &cobra.Command{
+	Use:   "bench <owner/model[:version]|owner/deployment> [input=value] ... [flags]",
+	Short: "Measure the latency of a model or deployment",
+	Long: `Run a number of predictions with the same inputs, some at a time,
+and report percentiles of their queue time (from created to started),
+predict time and end-to-end latency, their failure rate and cold starts.
+Models that stream output also report the time to their first token.
+
+A prediction is counted as a cold start if it's queued for at least --cold-start.`,
+	Example: `  replicate bench stability-ai/sdxl prompt="a corgi" -n 50 -c 5
+  replicate bench acme/text-to-image --deployment prompt="a corgi" -n 20 --json`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completion.ModelInputs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		n, _ := cmd.Flags().GetInt("count")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		if n < 1 || concurrency < 1 {
+			return util.InvalidInputf("--count and --concurrency must be at least 1")
+		}
+		coldStart, _ := cmd.Flags().GetDuration("cold-start")
+		interval, _ := cmd.Flags().GetDuration("poll-interval")
+		isDeployment := cmd.Flags().Changed("deployment")
+
+		id, err := identifier.ParseIdentifier(args[0])
+		if err != nil {
+			if isDeployment {
+				return util.InvalidInputf("invalid deployment specified: %s", args[0])
+			}
+			return util.InvalidInputf("invalid model specified: %s", args[0])
+		}
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		var version *replicate.ModelVersion
+		var inputSchema, outputSchema *openapi3.Schema
+		if isDeployment {
+			d, err := r8.GetDeployment(ctx, id.Owner, id.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get deployment: %w", err)
+			}
+			inputSchema, outputSchema, err = deployment.GetReleaseSchemas(ctx, r8, d)
+			if err != nil {
+				return err
+			}
+		} else {
+			version = prediction.GetModelVersion(ctx, r8, id)
+			if version != nil {
+				inputSchema, outputSchema, err = util.GetSchemas(*version)
+				if err != nil {
+					return fmt.Errorf("failed to get input schema for version: %w", err)
+				}
+			}
+		}
+
+		inputs, _, err := prediction.ParseInputArgs(cmd, r8, args[1:], inputSchema)
+		if err != nil {
+			return err
+		}
+
+		stream := prediction.ShouldStream(cmd, outputSchema)
+		create := func(ctx context.Context) (*replicate.Prediction, error) {
+			if isDeployment {
+				return r8.CreatePredictionWithDeployment(ctx, id.Owner, id.Name, inputs, nil, stream)
+			}
+			return prediction.CreatePrediction(ctx, r8, id, version, inputs, nil, stream)
+		}
+
+		// The progress bar is only shown in a terminal, since it's redrawn
+		bar := progressbar.DefaultSilent(int64(n), "benchmarking")
+		if isatty.IsTerminal(os.Stderr.Fd()) && !cmd.Flags().Changed("json") {
+			bar = progressbar.Default(int64(n), "benchmarking")
+		}
+
+		results := make([]*benchResult, n)
+
+		start := time.Now()
+		g := errgroup.Group{}
+		g.SetLimit(concurrency)
+		for i := 0; i < n; i++ {
+			i := i
+			g.Go(func() error {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				results[i] = runBenchPrediction(ctx, r8, create, stream, interval)
+				_ = bar.Add(1)
+				return nil
+			})
+		}
+		_ = g.Wait()
+		wallTime := time.Since(start)
+
+		if ctx.Err() != nil {
+			_ = bar.Exit()
+
+			canceled := 0
+			for _, r := range results {
+				if r != nil && r.interrupted {
+					canceled++
+				}
+			}
+			return util.Interruptedf("benchmark interrupted, and %d running prediction(s) were canceled", canceled)
+		}
+		_ = bar.Finish()
+
+		report := newBenchReport(args[0], isDeployment, concurrency, results, coldStart, wallTime)
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal benchmark: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		return writeBenchTable(report)
+	},
+}
+
+// benchResult is what was measured for one prediction.
+// Times are in seconds.
+type benchResult struct {
+	ID               string           `json:"id,omitempty"`
+	Status           replicate.Status `json:"status,omitempty"`
+	Error            string           `json:"error,omitempty"`
+	QueueTime        *float64         `json:"queue_time,omitempty"`
+	PredictTime      *float64         `json:"predict_time,omitempty"`
+	Latency          float64          `json:"latency"`
+	TimeToFirstToken *float64         `json:"time_to_first_token,omitempty"`
+	ColdStart        bool             `json:"cold_start"`
+
+	// interrupted is set if the benchmark was interrupted while the prediction was running
+	interrupted bool
+}
+
+// runBenchPrediction creates a prediction and streams or waits for it.
+// If ctx is canceled first, the prediction is canceled,
+// since benchmark predictions aren't wanted once interrupted.
+func runBenchPrediction(ctx context.Context, r8 *replicate.Client, create func(ctx context.Context) (*replicate.Prediction, error), stream bool, interval time.Duration) *benchResult {
+	result := &benchResult{}
+
+	start := time.Now()
+	p, err := create(ctx)
+	if err != nil {
+		result.Latency = time.Since(start).Seconds()
+		result.Error = err.Error()
+		return result
+	}
+	result.ID = p.ID
+
+	if stream && p.URLs["stream"] != "" {
+		ttft, err := streamBenchPrediction(ctx, r8, p, start)
+		result.TimeToFirstToken = ttft
+		result.Latency = time.Since(start).Seconds()
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+
+	// Streamed predictions are also waited for, to get their status and timestamps
+	streamed := result.Latency > 0
+	if !p.Status.Terminated() && ctx.Err() == nil {
+		if err := r8.Wait(ctx, p, replicate.WithPollingInterval(interval)); err != nil && result.Error == "" {
+			result.Error = err.Error()
+		}
+	}
+	if !streamed {
+		result.Latency = time.Since(start).Seconds()
+	}
+
+	if ctx.Err() != nil && !p.Status.Terminated() {
+		// The context is canceled, so canceling needs another
+		cancelCtx, stop := context.WithTimeout(context.Background(), 30*time.Second)
+		defer stop()
+
+		result.interrupted = true
+		if canceled, err := r8.CancelPrediction(cancelCtx, p.ID); err == nil {
+			p = canceled
+		}
+	}
+
+	result.Status = p.Status
+	if p.Error != nil {
+		result.Error = fmt.Sprint(p.Error)
+	}
+	if p.Metrics != nil {
+		result.PredictTime = p.Metrics.PredictTime
+	}
+	if p.StartedAt != nil {
+		created, err1 := time.Parse(time.RFC3339Nano, p.CreatedAt)
+		started, err2 := time.Parse(time.RFC3339Nano, *p.StartedAt)
+		if err1 == nil && err2 == nil {
+			queueTime := started.Sub(created).Seconds()
+			result.QueueTime = &queueTime
+		}
+	}
+
+	return result
+}
+
+// streamBenchPrediction streams a prediction's output until it's done,
+// returning the time from start to its first output
+func streamBenchPrediction(ctx context.Context, r8 *replicate.Client, p *replicate.Prediction, start time.Time) (*float64, error) {
+	events, errs := r8.StreamPrediction(ctx, p)
+
+	var ttft *float64
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return ttft, nil
+			}
+
+			switch event.Type {
+			case replicate.SSETypeOutput:
+				if ttft == nil {
+					seconds := time.Since(start).Seconds()
+					ttft = &seconds
+				}
+			case replicate.SSETypeDone:
+				return ttft, nil
+			}
+		case err, ok := <-errs:
+			if !ok || ctx.Err() != nil {
+				return ttft, nil
+			}
+			return ttft, fmt.Errorf("streaming error: %w", err)
+		case <-ctx.Done():
+			return ttft, nil
+		}
+	}
+}
+
+type benchReport struct {
+	Target           string         `json:"target"`
+	Deployment       bool           `json:"deployment"`
+	Count            int            `json:"count"`
+	Concurrency      int            `json:"concurrency"`
+	WallTime         float64        `json:"wall_time"`
+	Failures         int            `json:"failures"`
+	FailureRate      float64        `json:"failure_rate"`
+	ColdStarts       int            `json:"cold_starts"`
+	QueueTime        util.Stats     `json:"queue_time"`
+	PredictTime      util.Stats     `json:"predict_time"`
+	Latency          util.Stats     `json:"latency"`
+	TimeToFirstToken *util.Stats    `json:"time_to_first_token,omitempty"`
+	Predictions      []*benchResult `json:"predictions"`
+}
+
+// newBenchReport summarizes the results of a benchmark.
+// Times are summarized for predictions that succeeded.
+func newBenchReport(target string, isDeployment bool, concurrency int, results []*benchResult, coldStart time.Duration, wallTime time.Duration) *benchReport {
+	report := &benchReport{
+		Target:      target,
+		Deployment:  isDeployment,
+		Count:       len(results),
+		Concurrency: concurrency,
+		WallTime:    wallTime.Seconds(),
+		Predictions: results,
+	}
+
+	var queueTimes, predictTimes, latencies, ttfts []float64
+	for _, r := range results {
+		if r.QueueTime != nil && *r.QueueTime >= coldStart.Seconds() {
+			r.ColdStart = true
+			report.ColdStarts++
+		}
+
+		if r.Status != replicate.Succeeded {
+			report.Failures++
+			continue
+		}
+
+		if r.QueueTime != nil {
+			queueTimes = append(queueTimes, *r.QueueTime)
+		}
+		if r.PredictTime != nil {
+			predictTimes = append(predictTimes, *r.PredictTime)
+		}
+		latencies = append(latencies, r.Latency)
+		if r.TimeToFirstToken != nil {
+			ttfts = append(ttfts, *r.TimeToFirstToken)
+		}
+	}
+
+	report.FailureRate = float64(report.Failures) / float64(report.Count)
+	report.QueueTime = util.Summarize(queueTimes)
+	report.PredictTime = util.Summarize(predictTimes)
+	report.Latency = util.Summarize(latencies)
+	if len(ttfts) > 0 {
+		stats := util.Summarize(ttfts)
+		report.TimeToFirstToken = &stats
+	}
+
+	return report
+}
+
+func writeBenchTable(report *benchReport) error {
+	kind := "model"
+	if report.Deployment {
+		kind = "deployment"
+	}
+	fmt.Printf("Benchmarked %s %s with %d prediction(s), %d at a time, in %s\n\n",
+		kind, report.Target, report.Count, report.Concurrency, formatSeconds(report.WallTime))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tP50\tP90\tP99\tMEAN\tMIN\tMAX")
+
+	row := func(label string, stats util.Stats) {
+		cells := []string{label}
+		for _, v := range []float64{stats.P50, stats.P90, stats.P99, stats.Mean, stats.Min, stats.Max} {
+			cells = append(cells, formatSeconds(v))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	row("Queue time", report.QueueTime)
+	row("Predict time", report.PredictTime)
+	row("Latency", report.Latency)
+	if report.TimeToFirstToken != nil {
+		row("Time to first token", *report.TimeToFirstToken)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nFailures: %d of %d (%.1f%%)\n", report.Failures, report.Count, report.FailureRate*100)
+
+	coldStarts := []string{}
+	for _, r := range report.Predictions {
+		if r.ColdStart {
+			coldStarts = append(coldStarts, r.ID)
+		}
+	}
+	fmt.Printf("Cold starts: %d\n", report.ColdStarts)
+	if len(coldStarts) > 0 {
+		fmt.Printf("  %s\n", strings.Join(coldStarts, "\n  "))
+	}
+
+	return nil
+}
+
+func init() {
+	addBenchFlags(BenchCmd)
+}
+
+func addBenchFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("count", "n", 10, "Number of predictions to run")
+	cmd.Flags().IntP("concurrency", "c", 1, "Number of predictions to run at a time")
+	cmd.Flags().Bool("deployment", false, "Benchmark a deployment instead of a model")
+	cmd.Flags().Duration("cold-start", 10*time.Second, "Queue time at which a prediction counts as a cold start")
+	cmd.Flags().Duration("poll-interval", 250*time.Millisecond, "How often to check on predictions that aren't streamed, which limits the precision of their latency")
+	cmd.Flags().Bool("no-stream", false, "Don't stream output, even if the model supports it")
+
+	cmd.Flags().String("separator", "=", "Separator between input key and value")
+	prediction.AddInputDocumentFlags(cmd)
+
+	cmd.Flags().Bool("json", false, "Emit JSON")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
 	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 )
@@ -101,9 +102,11 @@ func init() {
 func addCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().String("model", "", "Model to deploy")
 	_ = cmd.MarkFlagRequired("model")
+	_ = cmd.RegisterFlagCompletionFunc("model", completion.Models)
 
 	cmd.Flags().String("hardware", "", "SKU of the hardware to run the model")
 	_ = cmd.MarkFlagRequired("hardware")
+	_ = cmd.RegisterFlagCompletionFunc("hardware", completion.Hardware)
 
 	cmd.Flags().Int("min-instances", 0, "Minimum number of instances to run the model")
 	cmd.Flags().Int("max-instances", 0, "Maximum number of instances to run the model")
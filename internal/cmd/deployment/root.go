@@ -26,14 +26,14 @@ func init() {
 		cmd.GroupID = "subcommand"
 	}
 
-	// RootCmd.AddGroup(&cobra.Group{
-	// 	ID:    "alias",
-	// 	Title: "Alias commands:",
-	// })
-	// for _, cmd := range []*cobra.Command{
-	// 	runCmd,
-	// } {
-	// 	RootCmd.AddCommand(cmd)
-	// 	cmd.GroupID = "alias"
-	// }
+	RootCmd.AddGroup(&cobra.Group{
+		ID:    "alias",
+		Title: "Alias commands:",
+	})
+	for _, cmd := range []*cobra.Command{
+		runCmd,
+	} {
+		RootCmd.AddCommand(cmd)
+		cmd.GroupID = "alias"
+	}
 }
@@ -0,0 +1,116 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/cmd/prediction"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/util"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <[owner/]name> [input=value] ... [flags]",
+	Short: "Create a prediction with a deployment",
+	Example: `  replicate deployment run acme/text-to-image prompt="a corgi"
+  replicate deployment run acme/text-to-image prompt="a corgi" --no-wait \
+    --webhook https://example.com/hooks/replicate --webhook-events completed`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completion.First(completion.Deployments),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := spinner.New(spinner.CharSets[21], 100*time.Millisecond)
+		s.FinalMSG = ""
+
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+		if !strings.Contains(name, "/") {
+			account, err := r8.GetCurrentAccount(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get current account: %w", err)
+			}
+			name = fmt.Sprintf("%s/%s", account.Username, name)
+		}
+		id, err := identifier.ParseIdentifier(name)
+		if err != nil {
+			return util.InvalidInputf("invalid deployment specified: %s", name)
+		}
+
+		deployment, err := r8.GetDeployment(ctx, id.Owner, id.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		inputSchema, outputSchema, err := GetReleaseSchemas(ctx, r8, deployment)
+		if err != nil {
+			return err
+		}
+
+		inputs, uploads, err := prediction.ParseInputArgs(cmd, r8, args[1:], inputSchema)
+		if err != nil {
+			return err
+		}
+
+		webhook, err := prediction.WebhookFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		s.Start()
+		p, err := r8.CreatePredictionWithDeployment(ctx, id.Owner, id.Name, inputs, webhook, prediction.ShouldStream(cmd, outputSchema))
+		if err != nil {
+			return fmt.Errorf("failed to create prediction: %w", err)
+		}
+		s.Stop()
+
+		if err := prediction.HandlePrediction(cmd, r8, p); err != nil {
+			return err
+		}
+
+		if err := prediction.DeleteUploads(cmd, r8, p, uploads); err != nil {
+			return err
+		}
+
+		return util.CheckPrediction(p)
+	},
+}
+
+// GetReleaseSchemas returns the input and output schemas of the model version
+// in a deployment's current release, or nil schemas if the version can't be found
+func GetReleaseSchemas(ctx context.Context, r8 *replicate.Client, deployment *replicate.Deployment) (*openapi3.Schema, *openapi3.Schema, error) {
+	release := deployment.CurrentRelease
+	model, err := identifier.ParseIdentifier(release.Model)
+	if err != nil || release.Version == "" {
+		return nil, nil, nil
+	}
+
+	version, err := r8.GetModelVersion(ctx, model.Owner, model.Name, release.Version)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	inputSchema, outputSchema, err := util.GetSchemas(*version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get input schema for version: %w", err)
+	}
+
+	return inputSchema, outputSchema, nil
+}
+
+func init() {
+	prediction.AddCreateFlags(runCmd)
+}
@@ -9,16 +9,18 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
 	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 )
 
 var showCmd = &cobra.Command{
-	Use:     "show <[owner/]name> [flags]",
-	Short:   "Show a deployment",
-	Example: "replicate deployment show acme/text-to-image",
-	Args:    cobra.ExactArgs(1),
-	Aliases: []string{"view"},
+	Use:               "show <[owner/]name> [flags]",
+	Short:             "Show a deployment",
+	Example:           "replicate deployment show acme/text-to-image",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.First(completion.Deployments),
+	Aliases:           []string{"view"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 
@@ -37,7 +39,7 @@ var showCmd = &cobra.Command{
 		}
 		id, err := identifier.ParseIdentifier(name)
 		if err != nil {
-			return fmt.Errorf("invalid deployment specified: %s", name)
+			return util.InvalidInputf("invalid deployment specified: %s", name)
 		}
 
 		if cmd.Flags().Changed("web") {
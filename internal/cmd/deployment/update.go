@@ -10,16 +10,18 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
 	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 )
 
 // updateCmd represents the create command
 var updateCmd = &cobra.Command{
-	Use:     "update <[owner/]name> [flags]",
-	Short:   "Update an existing deployment",
-	Example: `replicate deployment update acme/text-to-image --max-instances=2`,
-	Args:    cobra.ExactArgs(1),
+	Use:               "update <[owner/]name> [flags]",
+	Short:             "Update an existing deployment",
+	Example:           `replicate deployment update acme/text-to-image --max-instances=2`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.First(completion.Deployments),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		r8, err := client.NewClient()
 		if err != nil {
@@ -36,7 +38,7 @@ var updateCmd = &cobra.Command{
 		}
 		deploymentID, err := identifier.ParseIdentifier(name)
 		if err != nil {
-			return fmt.Errorf("invalid deployment specified: %s", name)
+			return util.InvalidInputf("invalid deployment specified: %s", name)
 		}
 
 		opts := &replicate.UpdateDeploymentOptions{}
@@ -49,7 +51,7 @@ var updateCmd = &cobra.Command{
 			if strings.Contains(value, ":") {
 				modelID, err := identifier.ParseIdentifier(value)
 				if err != nil {
-					return fmt.Errorf("invalid model version specified: %s", value)
+					return util.InvalidInputf("invalid model version specified: %s", value)
 				}
 				version = modelID.Version
 			} else {
@@ -114,6 +116,7 @@ func init() {
 func addUpdateFlags(cmd *cobra.Command) {
 	cmd.Flags().String("version", "", "Version of the model to deploy")
 	cmd.Flags().String("hardware", "", "SKU of the hardware to run the model")
+	_ = cmd.RegisterFlagCompletionFunc("hardware", completion.Hardware)
 	cmd.Flags().Int("min-instances", 0, "Minimum number of instances to run the model")
 	cmd.Flags().Int("max-instances", 0, "Maximum number of instances to run the model")
 
@@ -0,0 +1,80 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/mockserver"
+)
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server [flags]",
+	Short: "Run a mock Replicate API server",
+	Long: `Run a local stand-in for the Replicate API, for trying the CLI and running tests offline.
+
+It serves a few fixture models and a deployment. Predictions and trainings
+progress each time they're fetched, and fail if their input has "fail": true.
+Point the CLI at it by setting REPLICATE_BASE_URL to the URL it prints.`,
+	Example: `  replicate dev mock-server --port 8787
+  REPLICATE_BASE_URL=http://127.0.0.1:8787 REPLICATE_API_TOKEN=test replicate run replicate/hello-world text=world`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		steps, _ := cmd.Flags().GetInt("steps")
+		pageSize, _ := cmd.Flags().GetInt("page-size")
+		token, _ := cmd.Flags().GetString("token")
+
+		handler := mockserver.New(
+			mockserver.WithSteps(steps),
+			mockserver.WithPageSize(pageSize),
+			mockserver.WithToken(token),
+		)
+
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprint(port)))
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+
+		server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		url := fmt.Sprintf("http://%s", ln.Addr())
+		fmt.Fprintf(os.Stderr, "Serving a mock Replicate API on %s\n", url)
+		fmt.Fprintf(os.Stderr, "Use it with: export REPLICATE_BASE_URL=%s\n", url)
+
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addMockServerFlags(mockServerCmd)
+}
+
+func addMockServerFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("port", "p", 8787, "Port to listen on")
+	cmd.Flags().String("host", "127.0.0.1", "Host to listen on")
+	cmd.Flags().Int("steps", 2, "Number of times a prediction or training is fetched before it finishes")
+	cmd.Flags().Int("page-size", 0, "Number of results in each page of a list, or 0 for a single page")
+	cmd.Flags().String("token", "", "API token that requests must have, or empty to accept any")
+}
@@ -0,0 +1,23 @@
+package dev
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var RootCmd = &cobra.Command{
+	Use:   "dev [subcommand]",
+	Short: "Tools for developing with Replicate",
+}
+
+func init() {
+	RootCmd.AddGroup(&cobra.Group{
+		ID:    "subcommand",
+		Title: "Subcommands:",
+	})
+	for _, cmd := range []*cobra.Command{
+		mockServerCmd,
+	} {
+		RootCmd.AddCommand(cmd)
+		cmd.GroupID = "subcommand"
+	}
+}
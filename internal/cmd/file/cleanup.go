@@ -0,0 +1,90 @@
+package file
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/util"
+)
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup [flags]",
+	Short: "Delete files uploaded for prediction inputs",
+	Long: `Delete files that were uploaded for @file inputs to predictions.
+
+Files uploaded with "replicate file upload" are kept.
+To delete input files as soon as a prediction finishes,
+pass --delete-uploads when you create it.`,
+	Example: `  replicate file cleanup --older-than 24h --dry-run`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		olderThan, _ := cmd.Flags().GetDuration("older-than")
+		dryRun := cmd.Flags().Changed("dry-run")
+
+		files, err := listFiles(ctx, r8, 0)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, file := range files {
+			if !uploadedForInput(file.Metadata) {
+				continue
+			}
+
+			if olderThan > 0 {
+				created, err := time.Parse(time.RFC3339, file.CreatedAt)
+				if err != nil || time.Since(created) < olderThan {
+					continue
+				}
+			}
+
+			if dryRun {
+				fmt.Printf("Would delete %s (%s)\n", file.ID, file.Name)
+			} else {
+				if err := r8.DeleteFile(ctx, file.ID); err != nil {
+					return fmt.Errorf("failed to delete file %s: %w", file.ID, err)
+				}
+				fmt.Printf("Deleted %s (%s)\n", file.ID, file.Name)
+			}
+			count++
+		}
+
+		if dryRun {
+			fmt.Printf("Would delete %d file(s)\n", count)
+		} else {
+			fmt.Printf("Deleted %d file(s)\n", count)
+		}
+
+		return nil
+	},
+}
+
+// uploadedForInput returns true if a file was uploaded for an @file input
+func uploadedForInput(metadata map[string]string) bool {
+	for k, v := range util.UploadMetadata {
+		if metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	addCleanupFlags(cleanupCmd)
+}
+
+func addCleanupFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("older-than", 0, "Only delete files uploaded at least this long ago")
+	cmd.Flags().Bool("dry-run", false, "List the files that would be deleted without deleting them")
+}
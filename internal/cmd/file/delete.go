@@ -0,0 +1,33 @@
+package file
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:     "delete <id>...",
+	Short:   "Delete uploaded files",
+	Args:    cobra.MinimumNArgs(1),
+	Aliases: []string{"rm"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		for _, id := range args {
+			if err := r8.DeleteFile(ctx, id); err != nil {
+				return fmt.Errorf("failed to delete file %s: %w", id, err)
+			}
+			fmt.Printf("Deleted %s\n", id)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,80 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/util"
+)
+
+var downloadCmd = &cobra.Command{
+	Use:   "download <id> [flags]",
+	Short: "Download an uploaded file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		file, err := r8.GetFile(ctx, args[0])
+		if file == nil || err != nil {
+			return fmt.Errorf("failed to get file: %w", err)
+		}
+
+		url := file.URLs["get"]
+		if url == "" {
+			return fmt.Errorf("file %s has no download URL", file.ID)
+		}
+
+		path, _ := cmd.Flags().GetString("output")
+		if path == "" {
+			path = filepath.Base(file.Name)
+			if path == "." || path == "/" {
+				path = file.ID
+			}
+		}
+
+		httpClient, err := client.NewHTTPClient()
+		if err != nil {
+			return err
+		}
+
+		downloader := &util.Downloader{
+			Client:   httpClient,
+			Progress: util.IsTTY(),
+		}
+		downloaded, err := downloader.DownloadFile(ctx, url, path)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(downloaded, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal file: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		fmt.Printf("Saved %s to %s\n", file.ID, downloaded.Path)
+
+		return nil
+	},
+}
+
+func init() {
+	addDownloadFlags(downloadCmd)
+}
+
+func addDownloadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "", "Path to save the file to, defaults to its name")
+	cmd.Flags().Bool("json", false, "Emit JSON")
+}
@@ -0,0 +1,150 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/tui"
+	"github.com/replicate/cli/internal/util"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List uploaded files",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		files, err := listFiles(ctx, r8, limit)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(files, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal files: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		columns := []table.Column{
+			{Title: "ID", Width: 30},
+			{Title: "Name", Width: 30},
+			{Title: "Type", Width: 20},
+			{Title: "Size", Width: 10},
+			{Title: "Created", Width: 20},
+		}
+
+		rows := []table.Row{}
+
+		for _, file := range files {
+			rows = append(rows, table.Row{
+				file.ID,
+				file.Name,
+				file.ContentType,
+				util.FormatBytes(int64(file.Size)),
+				file.CreatedAt,
+			})
+		}
+
+		t := tui.NewTable(columns, rows,
+			tui.WithActions(
+				tui.Action{
+					Key:  "s",
+					Help: "Show",
+					Run: func(row table.Row) tea.Cmd {
+						return tui.Exec("file", "show", row[0])
+					},
+				},
+				tui.Action{
+					Key:  "d",
+					Help: "Download",
+					Run: func(row table.Row) tea.Cmd {
+						return tui.Exec("file", "download", row[0])
+					},
+				},
+				tui.Action{
+					Key:     "x",
+					Help:    "Delete",
+					Confirm: true,
+					Run: func(row table.Row) tea.Cmd {
+						return func() tea.Msg {
+							if err := r8.DeleteFile(ctx, row[0]); err != nil {
+								return tui.ActionResult{Err: fmt.Errorf("failed to delete file: %w", err)}
+							}
+							return tui.ActionResult{ID: row[0], Message: "Deleted " + row[0], Remove: true}
+						}
+					},
+				},
+			),
+		)
+		if err := t.Run(); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+// listFiles returns up to limit files, fetching as many pages as needed.
+// A limit of zero or less returns every file.
+func listFiles(ctx context.Context, r8 *replicate.Client, limit int) ([]replicate.File, error) {
+	page, err := r8.ListFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	files := []replicate.File{}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results, errs := replicate.Paginate(ctx, r8, page)
+	for results != nil || errs != nil {
+		select {
+		case batch, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+
+			files = append(files, batch...)
+			if limit > 0 && len(files) >= limit {
+				return files[:limit], nil
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list files: %w", err)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func init() {
+	addListFlags(listCmd)
+}
+
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("limit", 100, "Maximum number of files to list, or 0 for all")
+	cmd.Flags().Bool("json", false, "Emit JSON")
+}
@@ -0,0 +1,29 @@
+package file
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var RootCmd = &cobra.Command{
+	Use:     "file [subcommand]",
+	Short:   "Interact with uploaded files",
+	Aliases: []string{"files", "f"},
+}
+
+func init() {
+	RootCmd.AddGroup(&cobra.Group{
+		ID:    "subcommand",
+		Title: "Subcommands:",
+	})
+	for _, cmd := range []*cobra.Command{
+		uploadCmd,
+		listCmd,
+		showCmd,
+		deleteCmd,
+		downloadCmd,
+		cleanupCmd,
+	} {
+		RootCmd.AddCommand(cmd)
+		cmd.GroupID = "subcommand"
+	}
+}
@@ -0,0 +1,82 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/util"
+)
+
+var showCmd = &cobra.Command{
+	Use:     "show <id>",
+	Short:   "Show an uploaded file",
+	Args:    cobra.ExactArgs(1),
+	Aliases: []string{"view"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		file, err := r8.GetFile(ctx, args[0])
+		if file == nil || err != nil {
+			return fmt.Errorf("failed to get file: %w", err)
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(file, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal file: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		fmt.Println(file.ID)
+		fmt.Println("Name: " + file.Name)
+		fmt.Println("Type: " + file.ContentType)
+		fmt.Println("Size: " + util.FormatBytes(int64(file.Size)))
+		fmt.Println("Created at: " + file.CreatedAt)
+		if file.ExpiresAt != "" {
+			fmt.Println("Expires at: " + file.ExpiresAt)
+		}
+		if url := file.URLs["get"]; url != "" {
+			fmt.Println("URL: " + url)
+		}
+
+		if len(file.Checksums) > 0 {
+			fmt.Println("Checksums:")
+			for _, key := range sortedKeys(file.Checksums) {
+				fmt.Printf("  %s: %s\n", key, file.Checksums[key])
+			}
+		}
+
+		if len(file.Metadata) > 0 {
+			fmt.Println("Metadata:")
+			for _, key := range sortedKeys(file.Metadata) {
+				fmt.Printf("  %s: %s\n", key, file.Metadata[key])
+			}
+		}
+
+		return nil
+	},
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	showCmd.Flags().Bool("json", false, "Emit JSON")
+}
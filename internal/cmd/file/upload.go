@@ -0,0 +1,78 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/util"
+)
+
+var uploadCmd = &cobra.Command{
+	Use:     "upload <path>... [flags]",
+	Short:   "Upload files",
+	Example: `  replicate file upload photo.jpg voice.wav --concurrency 2`,
+	Args:    cobra.MinimumNArgs(1),
+	Aliases: []string{"create"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		if concurrency < 1 {
+			return fmt.Errorf("concurrency must be at least 1")
+		}
+
+		files := make([]*replicate.File, len(args))
+
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(concurrency)
+		for i, path := range args {
+			i, path := i, path
+			g.Go(func() error {
+				file, err := r8.CreateFileFromPath(gctx, path, nil)
+				if err != nil {
+					return fmt.Errorf("failed to upload %s: %w", path, err)
+				}
+				files[i] = file
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(files, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal files: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		for i, file := range files {
+			fmt.Printf("%s\t%s\n", args[i], file.URLs["get"])
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addUploadFlags(uploadCmd)
+}
+
+func addUploadFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("concurrency", 4, "Number of files to upload at once")
+	cmd.Flags().Bool("json", false, "Emit JSON")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/pricing"
 	"github.com/replicate/cli/internal/util"
 )
 
@@ -51,8 +52,18 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
+		// Prices are shown for hardware they're known for,
+		// unless the prices file can't be read
+		prices, err := pricing.Load("")
+		if err != nil {
+			prices = &pricing.Table{}
+		}
 		for _, hw := range *hardware {
-			fmt.Printf("- %s: %s\n", hw.SKU, hw.Name)
+			if price, ok := prices.Price(hw.SKU); ok {
+				fmt.Printf("- %s: %s ($%.6f/s)\n", hw.SKU, hw.Name, price)
+			} else {
+				fmt.Printf("- %s: %s\n", hw.SKU, hw.Name)
+			}
 		}
 
 		return nil
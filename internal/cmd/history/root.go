@@ -0,0 +1,141 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/history"
+	"github.com/replicate/cli/internal/tui"
+	"github.com/replicate/cli/internal/util"
+)
+
+var RootCmd = &cobra.Command{
+	Use:   "history [query] [flags]",
+	Short: "List the predictions and trainings you've created",
+	Long: `List the predictions and trainings created with this CLI, newest first.
+
+Entries are numbered, and the number can be used instead of an ID,
+like "replicate prediction rerun 3" or "replicate training show 5".`,
+	Example: `  replicate history corgi
+  replicate history --model stability-ai/sdxl --status failed
+  replicate history show 3`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := history.Filter{}
+		if len(args) > 0 {
+			filter.Query = args[0]
+		}
+		filter.Kind, _ = cmd.Flags().GetString("kind")
+		filter.Model, _ = cmd.Flags().GetString("model")
+		filter.Status, _ = cmd.Flags().GetString("status")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		entries, err := history.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load history: %w", err)
+		}
+
+		matches := []history.Entry{}
+		for i := len(entries) - 1; i >= 0; i-- {
+			if limit > 0 && len(matches) == limit {
+				break
+			}
+			if filter.Match(entries[i]) {
+				matches = append(matches, entries[i])
+			}
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(matches, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal history: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		if len(matches) == 0 {
+			fmt.Println("No predictions or trainings found")
+			return nil
+		}
+
+		columns := []table.Column{
+			{Title: "#", Width: 5},
+			{Title: "ID", Width: 26},
+			{Title: "Model", Width: 30},
+			{Title: "", Width: 3},
+			{Title: "Created", Width: 20},
+		}
+
+		rows := []table.Row{}
+		kinds := map[string]string{}
+		for _, entry := range matches {
+			number := strconv.Itoa(entry.Number)
+			kinds[number] = entry.Kind
+			rows = append(rows, table.Row{
+				number,
+				entry.ID,
+				entry.Model,
+				util.StatusSymbol(entry.Status),
+				entry.CreatedAt,
+			})
+		}
+
+		t := tui.NewTable(columns, rows,
+			tui.WithURL(func(row table.Row) string {
+				return fmt.Sprintf("https://replicate.com/p/%s", row[1])
+			}),
+			tui.WithActions(
+				tui.Action{
+					Key:  "s",
+					Help: "Show",
+					Run: func(row table.Row) tea.Cmd {
+						return tui.Exec("history", "show", row[0])
+					},
+				},
+				tui.Action{
+					Key:  "r",
+					Help: "Run again",
+					Run: func(row table.Row) tea.Cmd {
+						if kinds[row[0]] != history.KindPrediction {
+							return func() tea.Msg {
+								return tui.ActionResult{Err: fmt.Errorf("only predictions can be run again")}
+							}
+						}
+						return tui.Exec("prediction", "rerun", row[0])
+					},
+				},
+			),
+		)
+
+		return t.Run()
+	},
+}
+
+func init() {
+	addListFlags(RootCmd)
+
+	RootCmd.AddGroup(&cobra.Group{
+		ID:    "subcommand",
+		Title: "Subcommands:",
+	})
+	for _, cmd := range []*cobra.Command{
+		showCmd,
+	} {
+		RootCmd.AddCommand(cmd)
+		cmd.GroupID = "subcommand"
+	}
+}
+
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("json", false, "Emit JSON")
+	cmd.Flags().String("kind", "", "Only show entries of a kind: prediction or training")
+	cmd.Flags().String("model", "", "Only show entries for a model, like owner/name")
+	cmd.Flags().String("status", "", "Only show entries with a status, like succeeded or failed")
+	cmd.Flags().IntP("limit", "n", 0, "Show at most this many entries, 0 for all")
+}
@@ -0,0 +1,112 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/history"
+	"github.com/replicate/cli/internal/util"
+)
+
+var (
+	labelStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Width(14)
+	sectionStyle = lipgloss.NewStyle().Bold(true).MarginTop(1)
+)
+
+var showCmd = &cobra.Command{
+	Use:     "show <number>",
+	Short:   "Show an entry in history",
+	Args:    cobra.ExactArgs(1),
+	Aliases: []string{"view"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		number, err := strconv.Atoi(args[0])
+		if err != nil {
+			return util.InvalidInputf("invalid history number: %s", args[0])
+		}
+
+		entry, err := history.Get(number)
+		if err != nil {
+			return &util.Error{Kind: util.KindNotFound, Err: err}
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(entry, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal history entry: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		fmt.Print(renderEntry(entry))
+
+		return nil
+	},
+}
+
+// renderEntry renders a history entry
+func renderEntry(entry *history.Entry) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s %s\n\n",
+		lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("#%d", entry.Number)),
+		entry.Kind,
+		lipgloss.NewStyle().Bold(true).Render(entry.ID))
+
+	field := func(label, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&b, "%s%s\n", labelStyle.Render(label), value)
+	}
+
+	field("Status", fmt.Sprintf("%s %s", util.StatusSymbol(entry.Status), entry.Status))
+	field("Model", entry.Model)
+	field("Version", entry.Version)
+	field("Created", entry.CreatedAt)
+	field("Started", entry.StartedAt)
+	field("Completed", entry.CompletedAt)
+	if entry.PredictTime != nil {
+		field("Predict time", fmt.Sprintf("%.1fs", *entry.PredictTime))
+	}
+	field("Command", entry.Command)
+
+	if len(entry.Input) > 0 {
+		b.WriteString(sectionStyle.Render("Inputs") + "\n")
+		keys := make([]string, 0, len(entry.Input))
+		for key := range entry.Input {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s%v\n", labelStyle.Render(key), entry.Input[key])
+		}
+	}
+
+	if entry.Output != nil {
+		b.WriteString(sectionStyle.Render("Output") + "\n")
+		bytes, err := json.MarshalIndent(entry.Output, "", "  ")
+		if err != nil {
+			fmt.Fprintf(&b, "%v\n", entry.Output)
+		} else {
+			b.WriteString(string(bytes) + "\n")
+		}
+	}
+
+	if entry.Error != nil {
+		b.WriteString(sectionStyle.Render("Error") + "\n")
+		fmt.Fprintf(&b, "%v\n", entry.Error)
+	}
+
+	return b.String()
+}
+
+func init() {
+	showCmd.Flags().Bool("json", false, "Emit JSON")
+}
@@ -4,15 +4,18 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/cmd/prediction"
+	"github.com/replicate/cli/internal/completion"
 )
 
 var runCmd = &cobra.Command{
-	Use:   "run <owner/model[:version]> [input=value] ... [flags]",
-	Short: `Alias for "prediction create"`,
-	Args:  cobra.MinimumNArgs(1),
-	RunE:  prediction.CreateCmd.RunE,
+	Use:               "run <owner/model[:version]> [input=value] ... [flags]",
+	Short:             `Alias for "prediction create"`,
+	Args:              prediction.CreateArgs,
+	ValidArgsFunction: completion.ModelInputs,
+	RunE:              prediction.CreateCmd.RunE,
 }
 
 func init() {
 	prediction.AddCreateFlags(runCmd)
+	prediction.AddLocalFlags(runCmd)
 }
@@ -3,8 +3,10 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
 	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 
@@ -13,14 +15,15 @@ import (
 )
 
 var schemaCmd = &cobra.Command{
-	Use:     "schema <owner/model[:version]>",
-	Short:   "Show the inputs and outputs of a model",
-	Args:    cobra.ExactArgs(1),
-	Example: `  replicate model schema stability-ai/sdxl`,
+	Use:               "schema <owner/model[:version]>",
+	Short:             "Show the inputs and outputs of a model",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.First(completion.Models),
+	Example:           `  replicate model schema stability-ai/sdxl`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		id, err := identifier.ParseIdentifier(args[0])
 		if err != nil {
-			return fmt.Errorf("invalid model specified: %s", args[0])
+			return util.InvalidInputf("invalid model specified: %s", args[0])
 		}
 
 		ctx := cmd.Context()
@@ -85,16 +88,16 @@ func printModelVersionSchema(version *replicate.ModelVersion) error {
 				}
 			}
 
-			fmt.Printf("- %s: %s (type: %s)\n", propName, description, prop.Value.Type)
+			fmt.Printf("- %s: %s (type: %s)\n", propName, description, strings.Join(prop.Value.Type.Slice(), ", "))
 		}
 		fmt.Println()
 	}
 
 	if outputSchema != nil {
 		fmt.Println("Output:")
-		fmt.Printf("- type: %s\n", outputSchema.Type)
+		fmt.Printf("- type: %s\n", strings.Join(outputSchema.Type.Slice(), ", "))
 		if outputSchema.Type.Is("array") {
-			fmt.Printf("- items: %s %s\n", outputSchema.Items.Value.Type, outputSchema.Items.Value.Format)
+			fmt.Printf("- items: %s %s\n", strings.Join(outputSchema.Items.Value.Type.Slice(), ", "), outputSchema.Items.Value.Format)
 		}
 		fmt.Println()
 	}
@@ -9,19 +9,21 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
 	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 )
 
 var showCmd = &cobra.Command{
-	Use:     "show <owner/model[:version]> [flags]",
-	Short:   "Show a model",
-	Args:    cobra.ExactArgs(1),
-	Aliases: []string{"view"},
+	Use:               "show <owner/model[:version]> [flags]",
+	Short:             "Show a model",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.First(completion.Models),
+	Aliases:           []string{"view"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		id, err := identifier.ParseIdentifier(args[0])
 		if err != nil {
-			return fmt.Errorf("invalid model specified: %s", args[0])
+			return util.InvalidInputf("invalid model specified: %s", args[0])
 		}
 
 		if cmd.Flags().Changed("web") {
@@ -0,0 +1,268 @@
+package prediction
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/history"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/util"
+)
+
+var (
+	columnStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("240")).
+			Padding(0, 1).
+			Width(60)
+	insertStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	deleteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// comparison is one side of a comparison between predictions
+type comparison struct {
+	Model       string                `json:"model"`
+	Prediction  *replicate.Prediction `json:"prediction"`
+	PredictTime *float64              `json:"predict_time,omitempty"`
+	Text        *string               `json:"text,omitempty"`
+	Files       []util.DownloadedFile `json:"files,omitempty"`
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <owner/model[:version]> <owner/model[:version]> [input=value] ... [flags]",
+	Short: "Run the same inputs through two models or versions and compare their outputs",
+	Example: `  replicate prediction compare meta/llama-2-7b-chat meta/llama-2-13b-chat prompt="Tell me a joke"
+  replicate prediction compare acme/model:abc123 acme/model:def456 image=@photo.jpg --json`,
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completion.Models,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids := make([]*identifier.Identifier, 2)
+		for i, arg := range args[:2] {
+			id, err := identifier.ParseIdentifier(arg)
+			if err != nil {
+				return util.InvalidInputf("invalid model specified: %s", arg)
+			}
+			ids[i] = id
+		}
+
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		// Files are uploaded once and shared by both predictions,
+		// and inputs are coerced for each model's schema below
+		inputs, uploads, err := ParseRawInputArgs(cmd, r8, args[2:])
+		if err != nil {
+			return err
+		}
+
+		dirname, _ := cmd.Flags().GetString("output-directory")
+		if dirname == "" {
+			dirname = "."
+		}
+
+		results := make([]*comparison, 2)
+		g, gctx := errgroup.WithContext(ctx)
+		for i, id := range ids {
+			i, id := i, id
+			g.Go(func() error {
+				version := GetModelVersion(gctx, r8, id)
+
+				var inputSchema *openapi3.Schema
+				if version != nil {
+					schema, _, err := util.GetSchemas(*version)
+					if err != nil {
+						return fmt.Errorf("failed to get input schema for %s: %w", id, err)
+					}
+					inputSchema = schema
+				}
+
+				coercedInputs, err := util.CoerceInputs(inputs, inputSchema)
+				if err != nil {
+					return util.InvalidInput(fmt.Errorf("failed to coerce inputs for %s: %w", id, err))
+				}
+
+				prediction, err := CreatePrediction(gctx, r8, id, version, coercedInputs, nil, false)
+				if err != nil {
+					return fmt.Errorf("failed to create prediction for %s: %w", id, err)
+				}
+				_ = history.RecordPrediction(prediction)
+
+				err = r8.Wait(gctx, prediction)
+				_ = history.RecordPrediction(prediction)
+				if err != nil {
+					return fmt.Errorf("failed to wait for prediction %s: %w", prediction.ID, err)
+				}
+
+				result := &comparison{
+					Model:      id.String(),
+					Prediction: prediction,
+				}
+				if prediction.Metrics != nil {
+					result.PredictTime = prediction.Metrics.PredictTime
+				}
+
+				if prediction.Status == replicate.Succeeded {
+					if files, _ := util.CollectOutputFiles(prediction.Output); len(files) > 0 {
+						dir, err := filepath.Abs(filepath.Join(dirname, prediction.ID))
+						if err != nil {
+							return fmt.Errorf("failed to create output directory: %w", err)
+						}
+
+						result.Files, err = (&util.Downloader{}).Download(gctx, *prediction, dir)
+						if err != nil {
+							return fmt.Errorf("failed to save output of %s: %w", prediction.ID, err)
+						}
+					} else if text, ok := outputText(prediction.Output); ok {
+						result.Text = &text
+					}
+				}
+
+				results[i] = result
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		// Both predictions have finished, so either can be checked
+		if err := DeleteUploads(cmd, r8, results[0].Prediction, uploads); err != nil {
+			return err
+		}
+
+		var diff []util.DiffLine
+		if results[0].Text != nil && results[1].Text != nil {
+			diff = util.Diff(*results[0].Text, *results[1].Text)
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			report := struct {
+				A    *comparison `json:"a"`
+				B    *comparison `json:"b"`
+				Diff *string     `json:"diff,omitempty"`
+			}{A: results[0], B: results[1]}
+
+			if diff != nil {
+				formatted := util.FormatDiff(results[0].Model, results[1].Model, diff)
+				report.Diff = &formatted
+			}
+
+			bytes, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal comparison: %w", err)
+			}
+			fmt.Println(string(bytes))
+		} else {
+			fmt.Println(lipgloss.JoinHorizontal(lipgloss.Top,
+				columnStyle.Render(renderComparison(results[0])),
+				columnStyle.Render(renderComparison(results[1])),
+			))
+
+			if diff != nil {
+				fmt.Println(sectionStyle.Render("Diff"))
+				fmt.Println("--- " + results[0].Model)
+				fmt.Println("+++ " + results[1].Model)
+				for _, line := range diff {
+					switch line.Op {
+					case util.DiffInsert:
+						fmt.Println(insertStyle.Render(line.String()))
+					case util.DiffDelete:
+						fmt.Println(deleteStyle.Render(line.String()))
+					default:
+						fmt.Println(line.String())
+					}
+				}
+			}
+		}
+
+		for _, result := range results {
+			if err := util.CheckPrediction(result.Prediction); err != nil {
+				return fmt.Errorf("%s: %w", result.Model, err)
+			}
+			if result.Prediction.Status != replicate.Succeeded {
+				return fmt.Errorf("prediction %s for %s has status %s", result.Prediction.ID, result.Model, result.Prediction.Status)
+			}
+		}
+
+		return nil
+	},
+}
+
+// renderComparison summarizes one side of a comparison
+func renderComparison(result *comparison) string {
+	var b strings.Builder
+
+	p := result.Prediction
+	fmt.Fprintf(&b, "%s\n", lipgloss.NewStyle().Bold(true).Render(result.Model))
+	fmt.Fprintf(&b, "%s%s\n", labelStyle.Render("Prediction"), p.ID)
+	fmt.Fprintf(&b, "%s%s\n", labelStyle.Render("Version"), p.Version)
+	fmt.Fprintf(&b, "%s%s %s\n", labelStyle.Render("Status"), util.StatusSymbol(p.Status), p.Status)
+	if result.PredictTime != nil {
+		fmt.Fprintf(&b, "%s%s\n", labelStyle.Render("Predict time"), formatSeconds(*result.PredictTime))
+	}
+
+	switch {
+	case len(result.Files) > 0:
+		b.WriteString(sectionStyle.Render("Files") + "\n")
+		for _, file := range result.Files {
+			fmt.Fprintf(&b, "- %s\n", file.Path)
+		}
+	case result.Text != nil:
+		b.WriteString(sectionStyle.Render("Output") + "\n")
+		b.WriteString(*result.Text + "\n")
+	case p.Error != nil:
+		b.WriteString(sectionStyle.Render("Error") + "\n")
+		b.WriteString(errorStyle.Render(fmt.Sprintf("%v", p.Error)) + "\n")
+	}
+
+	return b.String()
+}
+
+// outputText returns the output as text if it's a string,
+// or a list of strings like the tokens from a language model
+func outputText(output interface{}) (string, bool) {
+	switch v := output.(type) {
+	case string:
+		return v, true
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", false
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ""), true
+	}
+
+	return "", false
+}
+
+func init() {
+	addCompareFlags(compareCmd)
+}
+
+func addCompareFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("json", false, "Emit JSON")
+	cmd.Flags().String("separator", "=", "Separator between input key and value")
+	AddInputDocumentFlags(cmd)
+	AddFileInputFlags(cmd)
+	cmd.Flags().Bool("delete-uploads", false, "Delete files uploaded for @file inputs once both predictions finish")
+	cmd.Flags().StringP("output-directory", "o", "", "Directory for saved output files, defaults to the current directory")
+}
@@ -1,7 +1,9 @@
 package prediction
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,22 +17,43 @@ import (
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
+	"github.com/replicate/cli/internal/cache"
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/history"
 	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 )
 
 var CreateCmd = &cobra.Command{
-	Use:     "create <owner/model[:version]> [input=value] ... [flags]",
-	Short:   "Create a prediction",
-	Args:    cobra.MinimumNArgs(1),
-	Aliases: []string{"new", "run"},
+	Use:   "create <owner/model[:version]> [input=value] ... [flags]",
+	Short: "Create a prediction",
+	Long: `Create a prediction.
+
+Inputs are given as key=value and converted to the types in the model's schema.
+Values can also be read from other places:
+
+  key=@path           a file, uploaded or sent as a data URI
+  key=@-              stdin
+  key=env:NAME        an environment variable
+  key=pred:<id>#path  a value from another prediction
+  key:=<json>         raw JSON
+
+Put a backslash before @, env: or pred: to send a value as it is,
+quoted so the shell keeps it, like 'note=\env:HOME' or 'handle=\@corgi'.`,
+	Args:              CreateArgs,
+	ValidArgsFunction: completion.ModelInputs,
+	Aliases:           []string{"new", "run"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// TODO support running interactively
 
+		if url := LocalCogURL(cmd); url != "" {
+			return runLocal(cmd, url, args)
+		}
+
 		id, err := identifier.ParseIdentifier(args[0])
 		if err != nil {
-			return fmt.Errorf("invalid model specified: %s", args[0])
+			return util.InvalidInputf("invalid model specified: %s", args[0])
 		}
 
 		s := spinner.New(spinner.CharSets[21], 100*time.Millisecond)
@@ -43,27 +66,7 @@ var CreateCmd = &cobra.Command{
 			return err
 		}
 
-		var version *replicate.ModelVersion
-		if id.Version == "" {
-			if model, err := r8.GetModel(ctx, id.Owner, id.Name); err == nil {
-				version = model.LatestVersion
-			}
-		} else {
-			if v, err := r8.GetModelVersion(ctx, id.Owner, id.Name, id.Version); err == nil {
-				version = v
-			}
-		}
-
-		stdin, err := util.GetPipedArgs()
-		if err != nil {
-			return fmt.Errorf("failed to get stdin info: %w", err)
-		}
-
-		separator := cmd.Flag("separator").Value.String()
-		inputs, err := util.ParseInputs(ctx, r8, args[1:], stdin, separator)
-		if err != nil {
-			return fmt.Errorf("failed to parse inputs: %w", err)
-		}
+		version := GetModelVersion(ctx, r8, id)
 
 		var inputSchema *openapi3.Schema
 		var outputSchema *openapi3.Schema
@@ -74,214 +77,337 @@ var CreateCmd = &cobra.Command{
 			}
 		}
 
-		coercedInputs, err := util.CoerceTypes(inputs, inputSchema)
+		coercedInputs, uploads, err := ParseInputArgs(cmd, r8, args[1:], inputSchema)
 		if err != nil {
-			return fmt.Errorf("failed to coerce inputs: %w", err)
+			return err
 		}
 
-		shouldWait := (cmd.Flags().Changed("wait") || !cmd.Flags().Changed("no-wait"))
+		webhook, err := WebhookFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 
-		canStream := (outputSchema != nil &&
-			outputSchema.Type.Is("array") &&
-			outputSchema.Items.Value.Type.Is("string") &&
-			outputSchema.Extensions["x-cog-array-type"] == "iterator" &&
-			outputSchema.Extensions["x-cog-array-display"] == "concatenate")
-		shouldStream := canStream && !cmd.Flags().Changed("wait") &&
-			(cmd.Flags().Changed("stream") || !cmd.Flags().Changed("no-stream"))
+		shouldStream := ShouldStream(cmd, outputSchema)
 
 		s.Start()
-		var prediction *replicate.Prediction
-		if id.Version == "" {
-			prediction, err = r8.CreatePredictionWithModel(ctx, id.Owner, id.Name, coercedInputs, nil, shouldStream)
-			// TODO: check status code
-			if err != nil {
-				if version != nil {
-					prediction, err = r8.CreatePrediction(ctx, version.ID, coercedInputs, nil, shouldStream)
-				}
-			}
-		} else {
-			prediction, err = r8.CreatePrediction(ctx, id.Version, coercedInputs, nil, shouldStream)
-		}
+		prediction, err := CreatePrediction(ctx, r8, id, version, coercedInputs, webhook, shouldStream)
 		if err != nil {
 			return fmt.Errorf("failed to create prediction: %w", err)
 		}
 		s.Stop()
 
-		hasStream := prediction.URLs["stream"] != ""
+		if err := HandlePrediction(cmd, r8, prediction); err != nil {
+			return err
+		}
 
-		if !util.IsTTY() || cmd.Flags().Changed("json") {
-			if hasStream {
-				events, _ := r8.StreamPrediction(ctx, prediction)
+		if err := DeleteUploads(cmd, r8, prediction, uploads); err != nil {
+			return err
+		}
 
-				if cmd.Flags().Changed("json") {
-					fmt.Print("[")
-					defer fmt.Print("]")
+		return util.CheckPrediction(prediction)
+	},
+}
 
-					prefix := ""
-					for event := range events {
-						if event.Type != replicate.SSETypeOutput {
-							continue
-						}
+// GetModelVersion returns the specified version of a model, or its latest version.
+// It returns nil if the version can't be found.
+// Versions are cached for completing their inputs.
+func GetModelVersion(ctx context.Context, r8 *replicate.Client, id *identifier.Identifier) *replicate.ModelVersion {
+	var version *replicate.ModelVersion
+	if id.Version == "" {
+		if model, err := r8.GetModel(ctx, id.Owner, id.Name); err == nil {
+			version = model.LatestVersion
+		}
+	} else {
+		if v, err := r8.GetModelVersion(ctx, id.Owner, id.Name, id.Version); err == nil {
+			version = v
+		}
+	}
 
-						if event.Data == "" {
-							continue
-						}
+	_ = cache.SaveVersion(id.Owner, id.Name, version, id.Version == "")
 
-						b, err := json.Marshal(event.Data)
-						if err != nil {
-							return fmt.Errorf("failed to marshal event: %w", err)
-						}
+	return version
+}
 
-						fmt.Printf("%s%s", prefix, string(b))
-						prefix = ", "
-					}
-				} else {
-					for event := range events {
-						if event.Type != replicate.SSETypeOutput {
-							continue
-						}
+// CreatePrediction creates a prediction for a model identifier.
+// Predictions without a version are created with the model,
+// falling back to its latest version for models that don't support that.
+func CreatePrediction(ctx context.Context, r8 *replicate.Client, id *identifier.Identifier, version *replicate.ModelVersion, inputs replicate.PredictionInput, webhook *replicate.Webhook, stream bool) (*replicate.Prediction, error) {
+	if id.Version != "" {
+		return r8.CreatePrediction(ctx, id.Version, inputs, webhook, stream)
+	}
+
+	prediction, err := r8.CreatePredictionWithModel(ctx, id.Owner, id.Name, inputs, webhook, stream)
+	// TODO: check status code
+	if err != nil && version != nil {
+		prediction, err = r8.CreatePrediction(ctx, version.ID, inputs, webhook, stream)
+	}
+
+	return prediction, err
+}
 
-						fmt.Print(event.Data)
-					}
-					fmt.Println("")
-				}
+// ParseInputArgs parses input=value arguments and piped stdin,
+// and coerces the values to the types in the input schema.
+// It also returns the files uploaded for @file inputs.
+func ParseInputArgs(cmd *cobra.Command, r8 *replicate.Client, args []string, inputSchema *openapi3.Schema) (map[string]interface{}, []*replicate.File, error) {
+	inputs, uploads, err := ParseRawInputArgs(cmd, r8, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coercedInputs, err := util.CoerceInputs(inputs, inputSchema)
+	if err != nil {
+		return nil, nil, util.InvalidInput(fmt.Errorf("failed to coerce inputs: %w", err))
+	}
+
+	return coercedInputs, uploads, nil
+}
 
-				return nil
-			}
+// ParseRawInputArgs is like ParseInputArgs, but leaves the inputs to be coerced,
+// for inputs that are sent to more than one model
+func ParseRawInputArgs(cmd *cobra.Command, r8 *replicate.Client, args []string) (map[string]interface{}, []*replicate.File, error) {
+	stdin, err := util.GetPipedArgs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get stdin info: %w", err)
+	}
+
+	uploads := []*replicate.File{}
+	options := util.InputOptions{
+		Separator:  cmd.Flag("separator").Value.String(),
+		DataURIs:   LocalCogURL(cmd) != "" || cmd.Flags().Changed("data-uri"),
+		InlineText: cmd.Flags().Changed("inline-text"),
+		OnUpload: func(file *replicate.File) {
+			uploads = append(uploads, file)
+		},
+	}
+
+	inputFile, _ := cmd.Flags().GetString("input-file")
+	inputJSON, _ := cmd.Flags().GetString("input-json")
+	doc, err := util.LoadInputDocument(cmd.Context(), r8, inputFile, inputJSON, options)
+	if err != nil {
+		return nil, nil, util.InvalidInput(err)
+	}
+
+	inputs, err := util.ParseInputsWithOptions(cmd.Context(), r8, args, stdin, options)
+	if err != nil {
+		return nil, nil, util.InvalidInput(fmt.Errorf("failed to parse inputs: %w", err))
+	}
+
+	return util.MergeInputs(doc, inputs), uploads, nil
+}
 
-			if shouldWait {
-				err = r8.Wait(ctx, prediction)
-				if err != nil {
-					return fmt.Errorf("failed to wait for prediction: %w", err)
-				}
-			}
+// DeleteUploads deletes the files uploaded for a prediction's inputs
+// if the delete-uploads flag is set and the prediction has finished
+func DeleteUploads(cmd *cobra.Command, r8 *replicate.Client, prediction *replicate.Prediction, uploads []*replicate.File) error {
+	if !cmd.Flags().Changed("delete-uploads") || len(uploads) == 0 {
+		return nil
+	}
 
-			b, err := json.Marshal(prediction)
-			if err != nil {
-				return fmt.Errorf("failed to marshal prediction: %w", err)
-			}
-			fmt.Println(string(b))
+	ctx := cmd.Context()
+
+	// Streamed predictions aren't updated as they run
+	if !prediction.Status.Terminated() {
+		updated, err := r8.GetPrediction(ctx, prediction.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get prediction: %w", err)
+		}
 
+		if !updated.Status.Terminated() {
+			fmt.Fprintf(os.Stderr, "Kept %d uploaded file(s) because prediction %s hasn't finished. Delete them later with `replicate file cleanup`.\n", len(uploads), prediction.ID)
 			return nil
 		}
+	}
 
-		url := fmt.Sprintf("https://replicate.com/p/%s", prediction.ID)
-		if !hasStream {
-			fmt.Printf("Prediction created: %s\n", url)
+	for _, file := range uploads {
+		if err := r8.DeleteFile(ctx, file.ID); err != nil {
+			return fmt.Errorf("failed to delete uploaded file %s: %w", file.ID, err)
 		}
+	}
 
-		if cmd.Flags().Changed("web") {
-			if util.IsTTY() {
-				fmt.Println("Opening in browser...")
-			}
+	return nil
+}
 
-			err = browser.OpenURL(url)
-			if err != nil {
-				return fmt.Errorf("failed to open browser: %w", err)
-			}
+// WebhookFromFlags returns the webhook specified by the webhook flags,
+// or nil if there isn't one
+func WebhookFromFlags(cmd *cobra.Command) (*replicate.Webhook, error) {
+	url, _ := cmd.Flags().GetString("webhook")
+	events, _ := cmd.Flags().GetStringSlice("webhook-events")
 
+	webhook, err := util.ParseWebhook(url, events)
+	if err != nil {
+		return nil, util.InvalidInput(fmt.Errorf("failed to parse webhook: %w", err))
+	}
+
+	return webhook, nil
+}
+
+// ShouldStream returns true if output should be streamed,
+// based on the output schema and the stream and wait flags
+func ShouldStream(cmd *cobra.Command, outputSchema *openapi3.Schema) bool {
+	canStream := (outputSchema != nil &&
+		outputSchema.Type.Is("array") &&
+		outputSchema.Items.Value.Type.Is("string") &&
+		outputSchema.Extensions["x-cog-array-type"] == "iterator" &&
+		outputSchema.Extensions["x-cog-array-display"] == "concatenate")
+
+	return canStream && !cmd.Flags().Changed("wait") &&
+		(cmd.Flags().Changed("stream") || !cmd.Flags().Changed("no-stream"))
+}
+
+// HandlePrediction streams or waits for a newly created prediction,
+// and shows its output as specified by the create flags.
+// Predictions that have already finished aren't waited for.
+func HandlePrediction(cmd *cobra.Command, r8 *replicate.Client, prediction *replicate.Prediction) error {
+	ctx, cancel := WaitContext(cmd)
+	defer cancel()
+
+	// Predictions run with a local Cog server can't be looked up later
+	if r8 != nil {
+		_ = history.RecordPrediction(prediction)
+		defer func() { _ = history.RecordPrediction(prediction) }()
+	}
+
+	shouldWait := (cmd.Flags().Changed("wait") || !cmd.Flags().Changed("no-wait"))
+
+	hasStream := prediction.URLs["stream"] != ""
+
+	// Streaming and waiting stop early if the timeout passes or the command is interrupted
+	checkStopped := func(streamed bool) error {
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return handlePredictionTimeout(cmd, r8, prediction, !streamed)
+		case ctx.Err() != nil:
+			return handlePredictionInterrupt(cmd, r8, prediction)
+		default:
 			return nil
 		}
+	}
 
+	if !util.IsTTY() || cmd.Flags().Changed("json") {
 		if hasStream {
-			sseChan, errChan := r8.StreamPrediction(ctx, prediction)
+			events, _ := r8.StreamPrediction(ctx, prediction)
 
-			tokens := []string{}
-			for {
-				select {
-				case event, ok := <-sseChan:
-					if !ok {
-						return nil
-					}
+			if cmd.Flags().Changed("json") {
+				fmt.Print("[")
+				defer fmt.Print("]")
 
-					switch event.Type {
-					case replicate.SSETypeOutput:
-						token := event.Data
-						tokens = append(tokens, token)
-						fmt.Print(token)
-					case replicate.SSETypeLogs:
-						// TODO: print logs to stderr
-					case replicate.SSETypeDone:
-						return nil
-					default:
-						// ignore
+				prefix := ""
+				for event := range events {
+					if event.Type == replicate.SSETypeDone {
+						break
 					}
-				case err, ok := <-errChan:
-					if !ok {
-						return nil
+					if event.Type != replicate.SSETypeOutput {
+						continue
 					}
 
-					return fmt.Errorf("streaming error: %w", err)
-				}
-
-				if cmd.Flags().Changed("save") {
-					var dirname string
-					if cmd.Flags().Changed("output-directory") {
-						dirname = cmd.Flag("output-directory").Value.String()
-					} else {
-						dirname = fmt.Sprintf("./%s", prediction.ID)
+					if event.Data == "" {
+						continue
 					}
 
-					dir, err := filepath.Abs(dirname)
+					b, err := json.Marshal(event.Data)
 					if err != nil {
-						return fmt.Errorf("failed to create output directory: %w", err)
+						return fmt.Errorf("failed to marshal event: %w", err)
 					}
 
-					err = os.MkdirAll(dir, 0o755)
-					if err != nil {
-						return fmt.Errorf("failed to create directory: %w", err)
+					fmt.Printf("%s%s", prefix, string(b))
+					prefix = ", "
+				}
+			} else {
+				for event := range events {
+					if event.Type == replicate.SSETypeDone {
+						break
 					}
-
-					err = os.WriteFile(filepath.Join(dir, "output.txt"), []byte(strings.Join(tokens, "")), 0o644)
-					if err != nil {
-						return fmt.Errorf("failed to write output: %w", err)
+					if event.Type != replicate.SSETypeOutput {
+						continue
 					}
-				}
-			}
-		} else if shouldWait {
-			bar := progressbar.Default(100)
-			bar.Describe("processing")
 
-			predChan, errChan := r8.WaitAsync(ctx, prediction)
-			for pred := range predChan {
-				progress := pred.Progress()
-				if progress != nil {
-					bar.ChangeMax(progress.Total)
-					_ = bar.Set(progress.Current)
+					fmt.Print(event.Data)
 				}
+				fmt.Println("")
+			}
 
-				if pred.Status.Terminated() {
-					_ = bar.Finish()
-					break
-				}
+			if ctx.Err() == nil {
+				refreshPrediction(ctx, r8, prediction)
 			}
 
-			if err := <-errChan; err != nil {
+			return checkStopped(true)
+		}
+
+		if shouldWait && !prediction.Status.Terminated() {
+			err := r8.Wait(ctx, prediction)
+			if ctx.Err() != nil {
+				return checkStopped(false)
+			}
+			if err != nil {
 				return fmt.Errorf("failed to wait for prediction: %w", err)
 			}
+		}
 
-			switch prediction.Status {
-			case replicate.Succeeded:
-				fmt.Println("✅ Succeeded")
-				bytes, err := json.MarshalIndent(prediction.Output, "", "  ")
-				if err != nil {
-					return fmt.Errorf("failed to marshal output: %w", err)
+		b, err := json.Marshal(prediction)
+		if err != nil {
+			return fmt.Errorf("failed to marshal prediction: %w", err)
+		}
+		fmt.Println(string(b))
+
+		return nil
+	}
+
+	if LocalCogURL(cmd) != "" {
+		if cmd.Flags().Changed("web") {
+			return fmt.Errorf("predictions run with a local Cog server can't be viewed on the web")
+		}
+	} else if !hasStream {
+		fmt.Printf("Prediction created: https://replicate.com/p/%s\n", prediction.ID)
+	}
+
+	if cmd.Flags().Changed("web") {
+		url := fmt.Sprintf("https://replicate.com/p/%s", prediction.ID)
+		if util.IsTTY() {
+			fmt.Println("Opening in browser...")
+		}
+
+		err := browser.OpenURL(url)
+		if err != nil {
+			return fmt.Errorf("failed to open browser: %w", err)
+		}
+
+		return nil
+	}
+
+	if hasStream {
+		sseChan, errChan := r8.StreamPrediction(ctx, prediction)
+
+		tokens := []string{}
+		for {
+			select {
+			case event, ok := <-sseChan:
+				if !ok {
+					return checkStopped(true)
 				}
-				fmt.Println(string(bytes))
-			case replicate.Failed:
-				fmt.Println("❌ Failed")
-				fmt.Println(*prediction.Logs)
-				bytes, err := json.MarshalIndent(prediction.Error, "", "  ")
-				if err != nil {
-					return fmt.Errorf("error: %v", prediction.Error)
+
+				switch event.Type {
+				case replicate.SSETypeOutput:
+					token := event.Data
+					tokens = append(tokens, token)
+					fmt.Print(token)
+				case replicate.SSETypeLogs:
+					// TODO: print logs to stderr
+				case replicate.SSETypeDone:
+					refreshPrediction(ctx, r8, prediction)
+					return nil
+				default:
+					// ignore
 				}
-				fmt.Println(string(bytes))
-			case replicate.Canceled:
-				fmt.Println("🚫 Canceled")
-				fmt.Println(prediction.Logs)
+			case err, ok := <-errChan:
+				if !ok || ctx.Err() != nil {
+					return checkStopped(true)
+				}
+
+				return fmt.Errorf("streaming error: %w", err)
+			case <-ctx.Done():
+				return checkStopped(true)
 			}
 
-			if cmd.Flags().Changed("save") && prediction.Status == replicate.Succeeded {
+			if cmd.Flags().Changed("save") {
 				var dirname string
 				if cmd.Flags().Changed("output-directory") {
 					dirname = cmd.Flag("output-directory").Value.String()
@@ -294,20 +420,112 @@ var CreateCmd = &cobra.Command{
 					return fmt.Errorf("failed to create output directory: %w", err)
 				}
 
-				err = util.DownloadPrediction(ctx, *prediction, dir)
+				err = os.MkdirAll(dir, 0o755)
+				if err != nil {
+					return fmt.Errorf("failed to create directory: %w", err)
+				}
+
+				err = os.WriteFile(filepath.Join(dir, "output.txt"), []byte(strings.Join(tokens, "")), 0o644)
 				if err != nil {
-					return fmt.Errorf("failed to save output: %w", err)
+					return fmt.Errorf("failed to write output: %w", err)
 				}
 			}
 		}
+	} else if shouldWait {
+		if !prediction.Status.Terminated() {
+			bar := progressbar.Default(100)
+			bar.Describe("processing")
 
-		return nil
+			// Errors, including the context being canceled, are sent before the prediction channel closes
+			predChan, errChan := r8.WaitAsync(ctx, prediction)
+			var err error
+		wait:
+			for {
+				select {
+				case pred, ok := <-predChan:
+					if !ok {
+						break wait
+					}
 
-	},
+					progress := pred.Progress()
+					if progress != nil {
+						bar.ChangeMax(progress.Total)
+						_ = bar.Set(progress.Current)
+					}
+				case err = <-errChan:
+					break wait
+				}
+			}
+
+			if ctx.Err() != nil {
+				_ = bar.Exit()
+				return checkStopped(false)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to wait for prediction: %w", err)
+			}
+			_ = bar.Finish()
+		}
+
+		switch prediction.Status {
+		case replicate.Succeeded:
+			fmt.Println("✅ Succeeded")
+			bytes, err := json.MarshalIndent(prediction.Output, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal output: %w", err)
+			}
+			fmt.Println(string(bytes))
+		case replicate.Failed:
+			fmt.Println("❌ Failed")
+			fmt.Println(*prediction.Logs)
+			bytes, err := json.MarshalIndent(prediction.Error, "", "  ")
+			if err != nil {
+				return fmt.Errorf("error: %v", prediction.Error)
+			}
+			fmt.Println(string(bytes))
+		case replicate.Canceled:
+			fmt.Println("🚫 Canceled")
+			fmt.Println(prediction.Logs)
+		}
+
+		if cmd.Flags().Changed("save") && prediction.Status == replicate.Succeeded {
+			var dirname string
+			if cmd.Flags().Changed("output-directory") {
+				dirname = cmd.Flag("output-directory").Value.String()
+			} else {
+				dirname = fmt.Sprintf("./%s", prediction.ID)
+			}
+
+			dir, err := filepath.Abs(dirname)
+			if err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+
+			downloader := &util.Downloader{
+				Template: cmd.Flag("output-template").Value.String(),
+				Progress: util.IsTTY(),
+			}
+			_, err = downloader.Download(ctx, *prediction, dir)
+			if err != nil {
+				return fmt.Errorf("failed to save output: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// refreshPrediction updates a streamed prediction once its stream ends,
+// since streaming doesn't update its status or output
+func refreshPrediction(ctx context.Context, r8 *replicate.Client, prediction *replicate.Prediction) {
+	if updated, err := r8.GetPrediction(ctx, prediction.ID); err == nil {
+		*prediction = *updated
+	}
 }
 
 func init() {
 	AddCreateFlags(CreateCmd)
+	AddLocalFlags(CreateCmd)
 }
 
 func AddCreateFlags(cmd *cobra.Command) {
@@ -325,7 +543,35 @@ func AddCreateFlags(cmd *cobra.Command) {
 	cmd.MarkFlagsMutuallyExclusive("stream", "wait")
 
 	cmd.Flags().String("separator", "=", "Separator between input key and value")
+	AddInputDocumentFlags(cmd)
+	AddFileInputFlags(cmd)
+
+	AddWebhookFlags(cmd)
+
+	AddInterruptFlags(cmd)
+	AddTimeoutFlags(cmd)
+	cmd.MarkFlagsMutuallyExclusive("timeout", "no-wait")
+
+	cmd.Flags().Bool("delete-uploads", false, "Delete files uploaded for @file inputs once the prediction finishes")
+	cmd.MarkFlagsMutuallyExclusive("delete-uploads", "no-wait")
 
 	cmd.Flags().Bool("save", false, "Save prediction outputs to directory")
 	cmd.Flags().String("output-directory", "", "Output directory, defaults to ./{prediction-id}")
+	cmd.Flags().String("output-template", util.DefaultOutputTemplate, "Template for names of saved output files, like '{{.Index}}-{{.Basename}}'")
+}
+
+func AddInputDocumentFlags(cmd *cobra.Command) {
+	cmd.Flags().String("input-file", "", "JSON or YAML file of inputs, which input=value arguments override")
+	cmd.Flags().String("input-json", "", "JSON object of inputs, which input=value arguments override")
+	cmd.MarkFlagsMutuallyExclusive("input-file", "input-json")
+}
+
+func AddFileInputFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("inline-text", false, "Send text files of up to 64 kB given as @file inputs as their contents, instead of uploading them")
+	cmd.Flags().Bool("data-uri", false, "Send files given as @file inputs as data URIs, instead of uploading them")
+}
+
+func AddWebhookFlags(cmd *cobra.Command) {
+	cmd.Flags().String("webhook", "", "URL that receives the prediction or training as JSON on the events in --webhook-events")
+	cmd.Flags().StringSlice("webhook-events", nil, "Events that trigger the webhook: start, output, logs, completed")
 }
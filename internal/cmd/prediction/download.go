@@ -0,0 +1,137 @@
+package prediction
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/util"
+)
+
+var downloadCmd = &cobra.Command{
+	Use:   "download <id|url> [flags]",
+	Short: "Download the outputs of a prediction",
+	Example: `  replicate prediction download jpgp263bdekvxileu2ppsy46v4
+  replicate prediction download https://replicate.com/p/jpgp263bdekvxileu2ppsy46v4 -o ./corgi`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.First(completion.Predictions),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := util.ParsePredictionID(args[0])
+		if err != nil {
+			return util.InvalidInput(fmt.Errorf("failed to parse prediction ID: %w", err))
+		}
+
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		prediction, err := r8.GetPrediction(ctx, id)
+		if prediction == nil || err != nil {
+			return fmt.Errorf("failed to get prediction: %w", err)
+		}
+
+		if !prediction.Status.Terminated() {
+			if util.IsTTY() {
+				fmt.Printf("Waiting for prediction %s to finish...\n", prediction.ID)
+			}
+
+			err = r8.Wait(ctx, prediction)
+			if err != nil {
+				return fmt.Errorf("failed to wait for prediction: %w", err)
+			}
+		}
+
+		if err := util.CheckPrediction(prediction); err != nil {
+			return err
+		}
+		if prediction.Status != replicate.Succeeded {
+			return fmt.Errorf("prediction %s has status %s", prediction.ID, prediction.Status)
+		}
+
+		dirname, _ := cmd.Flags().GetString("output-directory")
+		if dirname == "" {
+			dirname = fmt.Sprintf("./%s", prediction.ID)
+		}
+
+		dir, err := filepath.Abs(dirname)
+		if err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		downloader := &util.Downloader{
+			Template: cmd.Flag("output-template").Value.String(),
+			Progress: util.IsTTY(),
+		}
+		files, err := downloader.Download(ctx, *prediction, dir)
+		if err != nil {
+			return fmt.Errorf("failed to save output: %w", err)
+		}
+
+		for i, file := range files {
+			if rel, err := filepath.Rel(dir, file.Path); err == nil {
+				files[i].Path = rel
+			}
+		}
+
+		err = writePredictionManifest(*prediction, files, dir)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			bytes, err := json.MarshalIndent(files, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal files: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
+		for _, file := range files {
+			fmt.Printf("- %s\n", filepath.Join(dirname, file.Path))
+		}
+		fmt.Printf("Saved %d file(s) to %s\n", len(files), dirname)
+
+		return nil
+	},
+}
+
+// writePredictionManifest writes prediction.json to dir,
+// with the prediction's inputs, metrics and logs, and the saved files
+func writePredictionManifest(prediction replicate.Prediction, files []util.DownloadedFile, dir string) error {
+	manifest := struct {
+		replicate.Prediction
+		Files []util.DownloadedFile `json:"files"`
+	}{prediction, files}
+
+	bytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal prediction: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "prediction.json"), bytes, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write prediction.json: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	addDownloadFlags(downloadCmd)
+}
+
+func addDownloadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output-directory", "o", "", "Output directory, defaults to ./{prediction-id}")
+	cmd.Flags().String("output-template", util.DefaultOutputTemplate, "Template for names of saved output files, like '{{.Index}}-{{.Basename}}'")
+	cmd.Flags().Bool("json", false, "Emit JSON")
+}
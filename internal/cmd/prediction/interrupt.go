@@ -0,0 +1,56 @@
+package prediction
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/util"
+)
+
+// HandleInterrupt cancels a prediction or training that was being waited for when the command was interrupted,
+// if the cancel-on-interrupt flag is set or the user chooses to when asked.
+// Otherwise it detaches, leaving it running.
+func HandleInterrupt(cmd *cobra.Command, kind string, id string, cancel func(ctx context.Context) error) error {
+	shouldCancel, _ := cmd.Flags().GetBool("cancel-on-interrupt")
+	if !shouldCancel && util.IsTTY() && isatty.IsTerminal(os.Stdin.Fd()) {
+		// Interrupting again while asked exits immediately
+		fmt.Fprintf(os.Stderr, "\nCancel %s %s? It keeps running otherwise. [y/N] ", kind, id)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		shouldCancel = answer == "y" || answer == "yes"
+	}
+
+	if !shouldCancel {
+		return util.Interruptedf("detached from %s %s, which is still running", kind, id)
+	}
+
+	// The command's context is canceled, so canceling needs another
+	ctx, stop := context.WithTimeout(context.Background(), 30*time.Second)
+	defer stop()
+
+	if err := cancel(ctx); err != nil {
+		return fmt.Errorf("failed to cancel %s %s: %w", kind, id, err)
+	}
+
+	return &util.Error{Kind: util.KindPredictionCanceled, Err: fmt.Errorf("%s %s was canceled", kind, id)}
+}
+
+// handlePredictionInterrupt handles an interrupt while waiting for a prediction
+func handlePredictionInterrupt(cmd *cobra.Command, r8 *replicate.Client, prediction *replicate.Prediction) error {
+	return HandleInterrupt(cmd, "prediction", prediction.ID, func(ctx context.Context) error {
+		_, err := r8.CancelPrediction(ctx, prediction.ID)
+		return err
+	})
+}
+
+func AddInterruptFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("cancel-on-interrupt", false, "Cancel when interrupted with Ctrl-C, instead of asking")
+}
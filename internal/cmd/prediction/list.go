@@ -3,58 +3,17 @@ package prediction
 import (
 	"encoding/json"
 	"fmt"
-	"os/exec"
 
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/tui"
 	"github.com/replicate/cli/internal/util"
 
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
-var baseStyle = lipgloss.NewStyle().
-	BorderStyle(lipgloss.NormalBorder()).
-	BorderForeground(lipgloss.Color("240"))
-
-type model struct {
-	table table.Model
-}
-
-func (m model) Init() tea.Cmd { return nil }
-
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	switch msg := msg.(type) { //nolint:gocritic
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
-			if m.table.Focused() {
-				m.table.Blur()
-			} else {
-				m.table.Focus()
-			}
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "enter":
-			selected := m.table.SelectedRow()
-			if len(selected) == 0 {
-				return m, nil
-			}
-			url := fmt.Sprintf("https://replicate.com/p/%s", selected[0])
-			return m, tea.ExecProcess(exec.Command("open", url), nil)
-		}
-	}
-	m.table, cmd = m.table.Update(msg)
-	return m, cmd
-}
-
-func (m model) View() string {
-	return baseStyle.Render(m.table.View()) + "\n"
-}
-
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List predictions",
@@ -98,27 +57,41 @@ var listCmd = &cobra.Command{
 			})
 		}
 
-		t := table.New(
-			table.WithColumns(columns),
-			table.WithRows(rows),
-			table.WithFocused(true),
-			table.WithHeight(30),
+		t := tui.NewTable(columns, rows,
+			tui.WithURL(func(row table.Row) string {
+				return fmt.Sprintf("https://replicate.com/p/%s", row[0])
+			}),
+			tui.WithActions(
+				tui.Action{
+					Key:  "s",
+					Help: "Show",
+					Run: func(row table.Row) tea.Cmd {
+						return tui.Exec("prediction", "show", row[0])
+					},
+				},
+				tui.Action{
+					Key:  "r",
+					Help: "Run again",
+					Run: func(row table.Row) tea.Cmd {
+						return tui.Exec("prediction", "rerun", row[0])
+					},
+				},
+				tui.Action{
+					Key:     "c",
+					Help:    "Cancel",
+					Confirm: true,
+					Run: func(row table.Row) tea.Cmd {
+						return tui.Result(row[0], func() (string, error) {
+							if _, err := r8.CancelPrediction(ctx, row[0]); err != nil {
+								return "", fmt.Errorf("failed to cancel prediction: %w", err)
+							}
+							return "Canceled " + row[0], nil
+						})
+					},
+				},
+			),
 		)
-
-		s := table.DefaultStyles()
-		s.Header = s.Header.
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			BorderBottom(true).
-			Bold(false)
-		s.Selected = s.Selected.
-			Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57")).
-			Bold(false)
-		t.SetStyles(s)
-
-		m := model{t}
-		if _, err := tea.NewProgram(m).Run(); err != nil {
+		if err := t.Run(); err != nil {
 			return err
 		}
 
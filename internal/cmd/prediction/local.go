@@ -0,0 +1,117 @@
+package prediction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/cog"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/util"
+)
+
+// LocalCogURL returns the URL of the local Cog server to run predictions with,
+// from the local flag or the REPLICATE_LOCAL_COG_URL environment variable.
+// It returns an empty string for commands without the local flag.
+func LocalCogURL(cmd *cobra.Command) string {
+	flag := cmd.Flags().Lookup("local")
+	if flag == nil {
+		return ""
+	}
+
+	if flag.Changed {
+		return flag.Value.String()
+	}
+
+	return os.Getenv("REPLICATE_LOCAL_COG_URL")
+}
+
+// CreateArgs validates the arguments to create a prediction.
+// A model is required unless the prediction is run with a local Cog server.
+func CreateArgs(cmd *cobra.Command, args []string) error {
+	if LocalCogURL(cmd) != "" {
+		return nil
+	}
+
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// runLocal runs a prediction with a local Cog server
+func runLocal(cmd *cobra.Command, baseURL string, args []string) error {
+	ctx := cmd.Context()
+
+	// A model can be given so the same arguments work for hosted and local runs,
+	// but the local server decides what's run
+	separator := cmd.Flag("separator").Value.String()
+	if len(args) > 0 && !strings.Contains(args[0], separator) {
+		if _, err := identifier.ParseIdentifier(args[0]); err == nil {
+			args = args[1:]
+		}
+	}
+
+	s := spinner.New(spinner.CharSets[21], 100*time.Millisecond)
+	s.FinalMSG = ""
+
+	c := cog.NewClient(baseURL)
+
+	version, err := c.GetVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Cog server at %s: %w", baseURL, err)
+	}
+
+	inputSchema, _, err := util.GetSchemas(*version)
+	if err != nil {
+		return fmt.Errorf("failed to get input schema: %w", err)
+	}
+
+	// Files are sent as data URIs, so there's no need for an API client
+	inputs, _, err := ParseInputArgs(cmd, nil, args, inputSchema)
+	if err != nil {
+		return err
+	}
+
+	if err := util.ValidateInputs(inputs, inputSchema); err != nil {
+		return util.InvalidInput(fmt.Errorf("invalid inputs: %w", err))
+	}
+
+	// The Cog server runs the prediction while the request is open,
+	// so it's stopped waiting for when interrupted or timed out
+	waitCtx, cancel := WaitContext(cmd)
+	defer cancel()
+
+	s.Start()
+	prediction, err := c.Predict(waitCtx, inputs)
+	s.Stop()
+	if err != nil {
+		switch {
+		case errors.Is(waitCtx.Err(), context.DeadlineExceeded):
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			return util.TimedOutf("local prediction didn't finish within %s", timeout)
+		case ctx.Err() != nil:
+			return util.Interruptedf("stopped waiting for local prediction")
+		}
+		return err
+	}
+
+	// Without an API client, there's no way to wait for a prediction that hasn't finished
+	if !prediction.Status.Terminated() {
+		return fmt.Errorf("the Cog server at %s returned a prediction with status %q instead of a finished one", baseURL, prediction.Status)
+	}
+
+	if err := HandlePrediction(cmd, nil, prediction); err != nil {
+		return err
+	}
+
+	return util.CheckPrediction(prediction)
+}
+
+func AddLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().String("local", "", "URL of a local Cog server to run the prediction with, like http://localhost:5000. Defaults to $REPLICATE_LOCAL_COG_URL")
+	cmd.MarkFlagsMutuallyExclusive("local", "web")
+}
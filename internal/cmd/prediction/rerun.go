@@ -0,0 +1,116 @@
+package prediction
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/util"
+)
+
+var rerunCmd = &cobra.Command{
+	Use:   "rerun <id|url> [input=value] ... [flags]",
+	Short: "Run a past prediction again, optionally with different inputs",
+	Example: `  replicate prediction rerun jpgp263bdekvxileu2ppsy46v4 seed=42
+  replicate prediction rerun https://replicate.com/p/jpgp263bdekvxileu2ppsy46v4 --latest`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completion.First(completion.Predictions),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		predictionID, err := util.ParsePredictionID(args[0])
+		if err != nil {
+			return util.InvalidInput(fmt.Errorf("failed to parse prediction ID: %w", err))
+		}
+
+		s := spinner.New(spinner.CharSets[21], 100*time.Millisecond)
+		s.FinalMSG = ""
+
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		previous, err := r8.GetPrediction(ctx, predictionID)
+		if previous == nil || err != nil {
+			return fmt.Errorf("failed to get prediction: %w", err)
+		}
+
+		// Predictions are created like with "prediction create",
+		// so official models without a pinned version run with the model
+		id, err := identifier.ParseIdentifier(previous.Model)
+		if err != nil {
+			if cmd.Flags().Changed("latest") {
+				return fmt.Errorf("prediction %s doesn't have a model, so its latest version can't be found", previous.ID)
+			}
+			id = &identifier.Identifier{}
+		}
+		id.Version = ""
+
+		var version *replicate.ModelVersion
+		if cmd.Flags().Changed("latest") {
+			version = GetModelVersion(ctx, r8, id)
+		} else if id.Owner == "" {
+			id.Version = previous.Version
+		} else if v, err := r8.GetModelVersion(ctx, id.Owner, id.Name, previous.Version); err == nil {
+			id.Version = v.ID
+			version = v
+		}
+
+		var inputSchema *openapi3.Schema
+		var outputSchema *openapi3.Schema
+		if version != nil {
+			inputSchema, outputSchema, err = util.GetSchemas(*version)
+			if err != nil {
+				return fmt.Errorf("failed to get input schema for version: %w", err)
+			}
+		}
+
+		overrides, uploads, err := ParseInputArgs(cmd, r8, args[1:], inputSchema)
+		if err != nil {
+			return err
+		}
+
+		inputs := replicate.PredictionInput{}
+		for k, v := range previous.Input {
+			inputs[k] = v
+		}
+		for k, v := range overrides {
+			inputs[k] = v
+		}
+
+		webhook, err := WebhookFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		s.Start()
+		prediction, err := CreatePrediction(ctx, r8, id, version, inputs, webhook, ShouldStream(cmd, outputSchema))
+		s.Stop()
+		if err != nil {
+			return fmt.Errorf("failed to create prediction: %w", err)
+		}
+
+		if err := HandlePrediction(cmd, r8, prediction); err != nil {
+			return err
+		}
+
+		if err := DeleteUploads(cmd, r8, prediction, uploads); err != nil {
+			return err
+		}
+
+		return util.CheckPrediction(prediction)
+	},
+}
+
+func init() {
+	AddCreateFlags(rerunCmd)
+	rerunCmd.Flags().Bool("latest", false, "Run on the model's latest version instead of the original one")
+}
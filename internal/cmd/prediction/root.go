@@ -19,6 +19,10 @@ func init() {
 		CreateCmd,
 		listCmd,
 		showCmd,
+		downloadCmd,
+		topCmd,
+		rerunCmd,
+		compareCmd,
 	} {
 		RootCmd.AddCommand(cmd)
 		cmd.GroupID = "subcommand"
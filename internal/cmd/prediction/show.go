@@ -1,23 +1,56 @@
 package prediction
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/cli/browser"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/replicate/replicate-go"
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 )
 
+const (
+	logsPaneHeight   = 15
+	maxInlineImages  = 4
+	inlineImageWidth = 60
+)
+
+var (
+	labelStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Width(14)
+	sectionStyle = lipgloss.NewStyle().Bold(true).MarginTop(1)
+	typeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	helpStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	logsStyle    = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("240"))
+)
+
 var showCmd = &cobra.Command{
-	Use:     "show <id>",
-	Short:   "Show a prediction",
-	Args:    cobra.ExactArgs(1),
-	Aliases: []string{"view"},
+	Use:               "show <id>",
+	Short:             "Show a prediction",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.First(completion.Predictions),
+	Aliases:           []string{"view"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := args[0]
+		id, err := util.ParsePredictionID(args[0])
+		if err != nil {
+			return util.InvalidInput(fmt.Errorf("failed to parse prediction ID: %w", err))
+		}
 
 		if cmd.Flags().Changed("web") {
 			if util.IsTTY() {
@@ -54,29 +87,271 @@ var showCmd = &cobra.Command{
 			return nil
 		}
 
-		// TODO: render prediction with TUI
-		fmt.Println(prediction.ID)
-		fmt.Println("Status: " + prediction.Status)
-
-		if prediction.CompletedAt != nil {
-			fmt.Println("Completed at: " + *prediction.CompletedAt)
-			fmt.Println("Inputs:")
-			for key, value := range prediction.Input {
-				fmt.Printf("  %s: %s\n", key, value)
+		// The schema is only used to describe inputs, so it's fine if it's missing
+		var inputSchema *openapi3.Schema
+		if id, err := identifier.ParseIdentifier(prediction.Model); err == nil && prediction.Version != "" {
+			if version, err := r8.GetModelVersion(ctx, id.Owner, id.Name, prediction.Version); err == nil {
+				inputSchema, _, _ = util.GetSchemas(*version)
 			}
+		}
 
-			fmt.Println("Outputs:")
-			bytes, err := json.MarshalIndent(prediction.Output, "", "  ")
-			if err != nil {
-				return fmt.Errorf("failed to marshal prediction output: %w", err)
-			}
-			fmt.Println(string(bytes))
+		fmt.Print(renderPrediction(prediction, inputSchema))
+
+		if protocol := util.DetectImageProtocol(); protocol != util.ImageProtocolNone {
+			renderOutputImages(ctx, prediction, protocol)
+		}
+
+		logs := ""
+		if prediction.Logs != nil {
+			logs = strings.TrimRight(*prediction.Logs, "\n")
+		}
+		if logs == "" {
+			return nil
+		}
+
+		fmt.Println(sectionStyle.Render("Logs"))
+		if strings.Count(logs, "\n") < logsPaneHeight {
+			fmt.Println(logsStyle.Render(logs))
+			return nil
+		}
+
+		// Long logs are shown in a scrollable pane
+		if _, err := tea.NewProgram(newLogsModel(logs)).Run(); err != nil {
+			return err
 		}
 
 		return nil
 	},
 }
 
+// renderPrediction renders everything about a prediction except its logs
+func renderPrediction(prediction *replicate.Prediction, inputSchema *openapi3.Schema) string {
+	var b strings.Builder
+
+	badge := lipgloss.NewStyle().
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("0")).
+		Background(statusColor(prediction.Status)).
+		Render(fmt.Sprintf("%s %s", util.StatusSymbol(prediction.Status), prediction.Status))
+	fmt.Fprintf(&b, "%s %s\n\n", badge, lipgloss.NewStyle().Bold(true).Render(prediction.ID))
+
+	field := func(label, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&b, "%s%s\n", labelStyle.Render(label), value)
+	}
+
+	field("Model", prediction.Model)
+	field("Version", prediction.Version)
+	field("Source", string(prediction.Source))
+	field("Timeline", renderTimeline(prediction))
+	if prediction.Metrics != nil && prediction.Metrics.PredictTime != nil {
+		field("Predict time", formatSeconds(*prediction.Metrics.PredictTime))
+	}
+
+	b.WriteString(sectionStyle.Render("Inputs") + "\n")
+	for _, key := range inputKeys(prediction.Input, inputSchema) {
+		value := prediction.Input[key]
+		fmt.Fprintf(&b, "%s%s %s\n", labelStyle.Render(key), formatValue(value), typeStyle.Render("("+inputType(key, value, inputSchema)+")"))
+	}
+
+	if prediction.Output != nil {
+		b.WriteString(sectionStyle.Render("Output") + "\n")
+		bytes, err := json.MarshalIndent(prediction.Output, "", "  ")
+		if err != nil {
+			fmt.Fprintf(&b, "%v\n", prediction.Output)
+		} else {
+			b.WriteString(string(bytes) + "\n")
+		}
+	}
+
+	if prediction.Error != nil {
+		b.WriteString(sectionStyle.Render("Error") + "\n")
+		b.WriteString(errorStyle.Render(fmt.Sprintf("%v", prediction.Error)) + "\n")
+	}
+
+	return b.String()
+}
+
+// renderOutputImages displays image outputs inline.
+// Images that can't be fetched or decoded are skipped.
+func renderOutputImages(ctx context.Context, prediction *replicate.Prediction, protocol util.ImageProtocol) {
+	files, _ := util.CollectOutputFiles(prediction.Output)
+
+	count := 0
+	for _, file := range files {
+		if count == maxInlineImages {
+			break
+		}
+		if !util.IsImageURL(file.URL) {
+			continue
+		}
+
+		data, err := util.FetchImage(ctx, file.URL)
+		if err != nil {
+			continue
+		}
+
+		if err := util.RenderImage(os.Stdout, data, protocol, inlineImageWidth); err == nil {
+			count++
+		}
+	}
+}
+
+// renderTimeline shows when a prediction was created, started and completed,
+// with the time spent between each step
+func renderTimeline(prediction *replicate.Prediction) string {
+	created, err := time.Parse(time.RFC3339Nano, prediction.CreatedAt)
+	if err != nil {
+		return prediction.CreatedAt
+	}
+
+	steps := []string{"created " + created.Local().Format(time.DateTime)}
+	last := created
+
+	for _, step := range []struct {
+		name string
+		at   *string
+	}{
+		{"started", prediction.StartedAt},
+		{"completed", prediction.CompletedAt},
+	} {
+		if step.at == nil {
+			break
+		}
+		t, err := time.Parse(time.RFC3339Nano, *step.at)
+		if err != nil {
+			break
+		}
+		steps = append(steps, fmt.Sprintf("%s +%s", step.name, t.Sub(last).Round(time.Millisecond)))
+		last = t
+	}
+
+	return strings.Join(steps, " → ")
+}
+
+func statusColor(status replicate.Status) lipgloss.Color {
+	switch status {
+	case replicate.Succeeded:
+		return lipgloss.Color("10")
+	case replicate.Failed:
+		return lipgloss.Color("9")
+	case replicate.Processing:
+		return lipgloss.Color("11")
+	case replicate.Canceled:
+		return lipgloss.Color("12")
+	default:
+		return lipgloss.Color("7")
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Millisecond).String()
+}
+
+// formatValue renders an input value as JSON, except for plain strings
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
+
+// inputKeys returns the keys of the inputs in schema order, if known
+func inputKeys(inputs replicate.PredictionInput, schema *openapi3.Schema) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	if schema != nil {
+		for _, key := range util.SortedKeys(schema.Properties) {
+			if _, ok := inputs[key]; ok {
+				keys = append(keys, key)
+				seen[key] = true
+			}
+		}
+	}
+
+	rest := []string{}
+	for key := range inputs {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// inputType returns the schema type of an input, or its JSON type if unknown
+func inputType(key string, value interface{}, schema *openapi3.Schema) string {
+	if schema != nil {
+		if prop, ok := schema.Properties[key]; ok && prop.Value != nil && prop.Value.Type != nil && len(*prop.Value.Type) > 0 {
+			t := strings.Join(*prop.Value.Type, "|")
+			if prop.Value.Format != "" {
+				t += ", " + prop.Value.Format
+			}
+			return t
+		}
+	}
+
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, int:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+type logsModel struct {
+	viewport viewport.Model
+}
+
+func newLogsModel(logs string) logsModel {
+	vp := viewport.New(100, logsPaneHeight)
+	vp.SetContent(logs)
+	vp.GotoBottom()
+	return logsModel{viewport: vp}
+}
+
+func (m logsModel) Init() tea.Cmd { return nil }
+
+func (m logsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		m.viewport.Width = msg.Width - 2
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m logsModel) View() string {
+	help := fmt.Sprintf("%3.f%% • ↑/↓ scroll • q quit", m.viewport.ScrollPercent()*100)
+	return logsStyle.Render(m.viewport.View()) + "\n" + helpStyle.Render(help) + "\n"
+}
+
 func init() {
 	showCmd.Flags().Bool("json", false, "Emit JSON")
 	showCmd.Flags().Bool("web", false, "Open in web browser")
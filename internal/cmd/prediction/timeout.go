@@ -0,0 +1,83 @@
+package prediction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/util"
+)
+
+// WaitContext returns the context for waiting for a prediction or training,
+// which is canceled when the duration of the timeout flag passes, if it's set
+func WaitContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		return context.WithCancel(cmd.Context())
+	}
+
+	return context.WithTimeout(cmd.Context(), timeout)
+}
+
+// HandleTimeout cancels a prediction or training that didn't finish before the timeout,
+// and shows the output and logs it had, unless they've already been streamed
+func HandleTimeout(cmd *cobra.Command, kind string, id string, cancel func(ctx context.Context) (*replicate.Prediction, error), showPartial bool) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	// The wait's context is done, so canceling needs another
+	ctx, stop := context.WithTimeout(context.Background(), 30*time.Second)
+	defer stop()
+
+	canceled, err := cancel(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to cancel %s %s after timing out: %w", kind, id, err)
+	}
+
+	if showPartial {
+		if err := showPartialPrediction(cmd, canceled); err != nil {
+			return err
+		}
+	}
+
+	return util.TimedOutf("%s %s didn't finish within %s and was canceled", kind, id, timeout)
+}
+
+// handlePredictionTimeout handles a prediction that didn't finish before the timeout
+func handlePredictionTimeout(cmd *cobra.Command, r8 *replicate.Client, prediction *replicate.Prediction, showPartial bool) error {
+	return HandleTimeout(cmd, "prediction", prediction.ID, func(ctx context.Context) (*replicate.Prediction, error) {
+		return r8.CancelPrediction(ctx, prediction.ID)
+	}, showPartial)
+}
+
+func showPartialPrediction(cmd *cobra.Command, prediction *replicate.Prediction) error {
+	if cmd.Flags().Changed("json") || !util.IsTTY() {
+		b, err := json.Marshal(prediction)
+		if err != nil {
+			return fmt.Errorf("failed to marshal prediction: %w", err)
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Println("⏱️ Timed out")
+	if prediction.Logs != nil && *prediction.Logs != "" {
+		fmt.Println(*prediction.Logs)
+	}
+	if prediction.Output != nil {
+		b, err := json.MarshalIndent(prediction.Output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal output: %w", err)
+		}
+		fmt.Println(string(b))
+	}
+
+	return nil
+}
+
+func AddTimeoutFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", 0, "Cancel if not finished after this long, like 10m")
+}
@@ -0,0 +1,426 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cli/browser"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/tui"
+	"github.com/replicate/cli/internal/util"
+)
+
+var statusFilters = []string{"", string(replicate.Starting), string(replicate.Processing), string(replicate.Succeeded), string(replicate.Failed), string(replicate.Canceled)}
+
+type predictionsMsg struct {
+	predictions []replicate.Prediction
+	err         error
+
+	// manual is set for refreshes with "r", which don't schedule another tick
+	manual bool
+}
+
+type tickMsg time.Time
+
+type actionMsg struct {
+	message string
+	err     error
+}
+
+type logsMsg struct {
+	id   string
+	logs string
+	err  error
+}
+
+type topModel struct {
+	ctx      context.Context
+	r8       *replicate.Client
+	interval time.Duration
+
+	predictions []replicate.Prediction
+	statuses    map[string]replicate.Status
+	transitions map[string]string
+	updatedAt   time.Time
+
+	statusFilter int
+	modelFilter  textinput.Model
+
+	table   table.Model
+	logs    viewport.Model
+	logsID  string
+	message string
+	err     error
+}
+
+func newTopModel(ctx context.Context, r8 *replicate.Client, interval time.Duration, status string, model string) (*topModel, error) {
+	m := &topModel{
+		ctx:         ctx,
+		r8:          r8,
+		interval:    interval,
+		statuses:    map[string]replicate.Status{},
+		transitions: map[string]string{},
+	}
+
+	for i, s := range statusFilters {
+		if s == status {
+			m.statusFilter = i
+		}
+	}
+	if status != "" && m.statusFilter == 0 {
+		return nil, util.InvalidInputf("invalid status %q, expected one of: %s", status, strings.Join(statusFilters[1:], ", "))
+	}
+
+	m.modelFilter = textinput.New()
+	m.modelFilter.Prompt = "model: "
+	m.modelFilter.SetValue(model)
+
+	m.table = tui.NewTableModel([]table.Column{
+		{Title: "ID", Width: 26},
+		{Title: "Model", Width: 30},
+		{Title: "", Width: 3},
+		{Title: "Status", Width: 24},
+		{Title: "Running", Width: 10},
+		{Title: "Created", Width: 20},
+	}, 20)
+
+	m.logs = viewport.New(100, 20)
+
+	return m, nil
+}
+
+func (m *topModel) Init() tea.Cmd {
+	return m.fetch(false)
+}
+
+func (m *topModel) fetch(manual bool) tea.Cmd {
+	return func() tea.Msg {
+		page, err := m.r8.ListPredictions(m.ctx)
+		if err != nil {
+			return predictionsMsg{err: err, manual: manual}
+		}
+		return predictionsMsg{predictions: page.Results, manual: manual}
+	}
+}
+
+func (m *topModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m *topModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		return m, m.fetch(false)
+	case predictionsMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.updatePredictions(msg.predictions)
+		}
+		// Only the tick loop schedules the next tick, so there's only ever one
+		if msg.manual {
+			return m, nil
+		}
+		return m, m.tick()
+	case actionMsg:
+		m.message, m.err = msg.message, msg.err
+		return m, nil
+	case logsMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.logsID = msg.id
+		m.logs.SetContent(msg.logs)
+		m.logs.GotoBottom()
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.table.SetHeight(max(msg.Height-8, 5))
+		m.logs.Width = msg.Width - 2
+		m.logs.Height = max(msg.Height-6, 5)
+	case tea.KeyMsg:
+		if m.modelFilter.Focused() {
+			switch msg.String() {
+			case "enter", "esc":
+				m.modelFilter.Blur()
+				m.table.Focus()
+				m.refreshRows()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.modelFilter, cmd = m.modelFilter.Update(msg)
+			m.refreshRows()
+			return m, cmd
+		}
+
+		if m.logsID != "" {
+			switch msg.String() {
+			case "q", "esc", "l":
+				m.logsID = ""
+				return m, nil
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			var cmd tea.Cmd
+			m.logs, cmd = m.logs.Update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "/":
+			m.modelFilter.Focus()
+			m.table.Blur()
+			return m, textinput.Blink
+		case "s":
+			m.statusFilter = (m.statusFilter + 1) % len(statusFilters)
+			m.refreshRows()
+			return m, nil
+		case "r":
+			return m, m.fetch(true)
+		case "c":
+			if id := m.selectedID(); id != "" {
+				return m, m.cancel(id)
+			}
+		case "o", "enter":
+			if id := m.selectedID(); id != "" {
+				return m, m.open(id)
+			}
+		case "l":
+			if id := m.selectedID(); id != "" {
+				return m, m.fetchLogs(id)
+			}
+		case "d":
+			if id := m.selectedID(); id != "" {
+				m.message = fmt.Sprintf("Downloading outputs of %s...", id)
+				return m, m.download(id)
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+// updatePredictions records status transitions since the last poll
+func (m *topModel) updatePredictions(predictions []replicate.Prediction) {
+	for _, p := range predictions {
+		if previous, ok := m.statuses[p.ID]; ok && previous != p.Status {
+			m.transitions[p.ID] = fmt.Sprintf("%s → %s", previous, p.Status)
+		}
+		m.statuses[p.ID] = p.Status
+	}
+
+	m.predictions = predictions
+	m.updatedAt = time.Now()
+	m.refreshRows()
+}
+
+func (m *topModel) refreshRows() {
+	status := statusFilters[m.statusFilter]
+	model := strings.ToLower(strings.TrimSpace(m.modelFilter.Value()))
+
+	rows := []table.Row{}
+	for _, p := range m.predictions {
+		if status != "" && string(p.Status) != status {
+			continue
+		}
+		if model != "" && !strings.Contains(strings.ToLower(p.Model), model) {
+			continue
+		}
+
+		transition, ok := m.transitions[p.ID]
+		if !ok {
+			transition = string(p.Status)
+		}
+
+		rows = append(rows, table.Row{
+			p.ID,
+			p.Model,
+			util.StatusSymbol(p.Status),
+			transition,
+			runningTime(p),
+			p.CreatedAt,
+		})
+	}
+
+	m.table.SetRows(rows)
+	if m.table.Cursor() >= len(rows) {
+		m.table.SetCursor(max(len(rows)-1, 0))
+	}
+}
+
+func (m *topModel) selectedID() string {
+	selected := m.table.SelectedRow()
+	if len(selected) == 0 {
+		return ""
+	}
+	return selected[0]
+}
+
+func (m *topModel) cancel(id string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := m.r8.CancelPrediction(m.ctx, id)
+		if err != nil {
+			return actionMsg{err: fmt.Errorf("failed to cancel prediction: %w", err)}
+		}
+		return actionMsg{message: fmt.Sprintf("Canceled %s", id)}
+	}
+}
+
+func (m *topModel) open(id string) tea.Cmd {
+	return func() tea.Msg {
+		err := browser.OpenURL(fmt.Sprintf("https://replicate.com/p/%s", id))
+		if err != nil {
+			return actionMsg{err: fmt.Errorf("failed to open browser: %w", err)}
+		}
+		return actionMsg{message: fmt.Sprintf("Opened %s in browser", id)}
+	}
+}
+
+func (m *topModel) fetchLogs(id string) tea.Cmd {
+	return func() tea.Msg {
+		prediction, err := m.r8.GetPrediction(m.ctx, id)
+		if err != nil {
+			return logsMsg{err: fmt.Errorf("failed to get prediction: %w", err)}
+		}
+
+		logs := "(no logs)"
+		if prediction.Logs != nil && *prediction.Logs != "" {
+			logs = *prediction.Logs
+		}
+		return logsMsg{id: id, logs: logs}
+	}
+}
+
+func (m *topModel) download(id string) tea.Cmd {
+	return func() tea.Msg {
+		prediction, err := m.r8.GetPrediction(m.ctx, id)
+		if err != nil {
+			return actionMsg{err: fmt.Errorf("failed to get prediction: %w", err)}
+		}
+
+		dir, err := filepath.Abs(prediction.ID)
+		if err != nil {
+			return actionMsg{err: err}
+		}
+
+		files, err := (&util.Downloader{}).Download(m.ctx, *prediction, dir)
+		if err != nil {
+			return actionMsg{err: fmt.Errorf("failed to save output: %w", err)}
+		}
+		return actionMsg{message: fmt.Sprintf("Saved %d file(s) to ./%s", len(files), prediction.ID)}
+	}
+}
+
+func (m *topModel) View() string {
+	if m.logsID != "" {
+		help := helpStyle.Render(fmt.Sprintf("logs for %s • %3.f%% • ↑/↓ scroll • esc back", m.logsID, m.logs.ScrollPercent()*100))
+		return logsStyle.Render(m.logs.View()) + "\n" + help + "\n"
+	}
+
+	status := statusFilters[m.statusFilter]
+	if status == "" {
+		status = "all"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "status: %s  %s  updated %s\n", status, m.modelFilter.View(), m.updatedAt.Format(time.TimeOnly))
+	b.WriteString(tui.RenderTable(m.table) + "\n")
+
+	switch {
+	case m.err != nil:
+		b.WriteString(errorStyle.Render(m.err.Error()) + "\n")
+	case m.message != "":
+		b.WriteString(m.message + "\n")
+	default:
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render("c cancel • o open • l logs • d download • s status • / model • r refresh • q quit") + "\n")
+
+	return b.String()
+}
+
+// runningTime is how long a prediction has been running, or ran for
+func runningTime(p replicate.Prediction) string {
+	created, err := time.Parse(time.RFC3339Nano, p.CreatedAt)
+	if err != nil {
+		return ""
+	}
+
+	end := time.Now()
+	if p.CompletedAt != nil {
+		if completed, err := time.Parse(time.RFC3339Nano, *p.CompletedAt); err == nil {
+			end = completed
+		}
+	}
+
+	return end.Sub(created).Round(time.Second).String()
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top [flags]",
+	Short: "Watch predictions as they run",
+	Example: `  replicate prediction top
+  replicate prediction top --status processing --model stability-ai/sdxl`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if !util.IsTTY() {
+			return fmt.Errorf("top requires an interactive terminal; use `replicate prediction list --json` instead")
+		}
+
+		ctx := cmd.Context()
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+		status, _ := cmd.Flags().GetString("status")
+		model, _ := cmd.Flags().GetString("model")
+
+		m, err := newTopModel(ctx, r8, interval, status, model)
+		if err != nil {
+			return err
+		}
+
+		// Keep the browser from writing over the dashboard
+		browser.Stdout = io.Discard
+		browser.Stderr = io.Discard
+
+		if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addTopFlags(topCmd)
+}
+
+func addTopFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("interval", 2*time.Second, "Interval between updates")
+	cmd.Flags().String("status", "", "Only show predictions with this status")
+	cmd.Flags().String("model", "", "Only show predictions for models matching this name")
+}
@@ -4,19 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/replicate/replicate-go"
 	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/util"
 )
 
 var ScaffoldCmd = &cobra.Command{
-	Use:   "scaffold <prediction-ID-or-URL> [<directory>] [--template=<template>]",
-	Short: "Create a new local development environment from a prediction",
-	Args:  cobra.RangeArgs(1, 2),
+	Use:               "scaffold <prediction-ID-or-URL> [<directory>] [--template=<template>]",
+	Short:             "Create a new local development environment from a prediction",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completion.First(completion.Predictions),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 
@@ -30,9 +33,9 @@ var ScaffoldCmd = &cobra.Command{
 			return err
 		}
 
-		predictionID, err := parsePredictionID(args[0])
+		predictionID, err := util.ParsePredictionID(args[0])
 		if err != nil {
-			return fmt.Errorf("failed to parse prediction ID: %w", err)
+			return util.InvalidInput(fmt.Errorf("failed to parse prediction ID: %w", err))
 		}
 		prediction, err := client.GetPrediction(ctx, predictionID)
 		if prediction == nil || err != nil {
@@ -63,52 +66,6 @@ func init() {
 	ScaffoldCmd.Flags().StringP("template", "t", "", "Starter git repo template to use. Currently supported: node, python")
 }
 
-// Parse the prediction id from a url, or return the prediction id if it's not a url
-func parsePredictionID(value string) (string, error) {
-	// Case 1: A prediction ID
-	if !strings.Contains(value, "/") {
-		return value, nil
-	}
-
-	// Case 2: A URL in the form https://replicate.com/p/{id}
-	if strings.HasPrefix(value, "replicate.com/p/") || strings.HasPrefix(value, "https://replicate.com/p/") {
-		splitURL := strings.Split(value, "/")
-		if len(splitURL) == 0 {
-			return "", fmt.Errorf("invalid URL format")
-		}
-		return splitURL[len(splitURL)-1], nil
-	}
-
-	// Case 3: A URL in the form https://api.replicate.com/v1/predictions/{id}
-	if strings.HasPrefix(value, "api.replicate.com/v1/predictions/") || strings.HasPrefix(value, "https://api.replicate.com/v1/predictions/") {
-		splitURL := strings.Split(value, "/")
-		if len(splitURL) == 0 {
-			return "", fmt.Errorf("invalid URL format")
-		}
-		return splitURL[len(splitURL)-1], nil
-	}
-
-	// Case 4: A URL in the form "https://replicate.com/*?prediction={id}"
-	if strings.Contains(value, "replicate.com") || strings.Contains(value, "https://replicate.com") {
-		parsedURL, err := url.Parse(value)
-		if err != nil {
-			return "", fmt.Errorf("failed to parse URL: %w", err)
-		}
-		queryParams, err := url.ParseQuery(parsedURL.RawQuery)
-		if err != nil {
-			return "", fmt.Errorf("failed to parse query parameters: %w", err)
-		}
-		predictionID := queryParams.Get("prediction")
-		if predictionID == "" {
-			return "", fmt.Errorf("no prediction ID found in URL")
-		}
-		return predictionID, nil
-	}
-
-	// If none of the above cases match, return an error
-	return "", fmt.Errorf("invalid prediction ID or URL format")
-}
-
 func execCommand(ctx context.Context, command string) error {
 	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
 	cmd.Stdout = os.Stdout
@@ -4,12 +4,14 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/cmd/prediction"
+	"github.com/replicate/cli/internal/completion"
 )
 
 var StreamCmd = &cobra.Command{
-	Use:   "stream <owner/model[:version]> [input=value] ... [flags]",
-	Short: `Alias for "prediction create --stream"`,
-	Args:  cobra.MinimumNArgs(1),
+	Use:               "stream <owner/model[:version]> [input=value] ... [flags]",
+	Short:             `Alias for "prediction create --stream"`,
+	Args:              prediction.CreateArgs,
+	ValidArgsFunction: completion.ModelInputs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		err := cmd.Flags().Set("stream", "true")
 		if err != nil {
@@ -22,4 +24,5 @@ var StreamCmd = &cobra.Command{
 
 func init() {
 	prediction.AddCreateFlags(StreamCmd)
+	prediction.AddLocalFlags(StreamCmd)
 }
@@ -4,13 +4,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/cmd/training"
+	"github.com/replicate/cli/internal/completion"
 )
 
 var TrainCmd = &cobra.Command{
-	Use:   "train <owner/model[:version]> [input=value] ... [flags]",
-	Short: `Alias for "training create"`,
-	Args:  cobra.MinimumNArgs(1),
-	RunE:  training.CreateCmd.RunE,
+	Use:               "train <owner/model[:version]> [input=value] ... [flags]",
+	Short:             `Alias for "training create"`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completion.First(completion.Models),
+	RunE:              training.CreateCmd.RunE,
 }
 
 func init() {
@@ -1,7 +1,9 @@
 package training
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,28 +13,39 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/cmd/prediction"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/history"
 	"github.com/replicate/cli/internal/identifier"
 	"github.com/replicate/cli/internal/util"
 )
 
 // CreateCmd represents the create command
 var CreateCmd = &cobra.Command{
-	Use:     "create <owner/model[:version]> --destination <owner/model> [input=value] ... [flags]",
-	Short:   "Create a training",
-	Args:    cobra.MinimumNArgs(1),
-	Aliases: []string{"new", "train"},
+	Use:               "create <owner/model[:version]> --destination <owner/model> [input=value] ... [flags]",
+	Short:             "Create a training",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completion.First(completion.Models),
+	Aliases:           []string{"new", "train"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// TODO support running interactively
 
+		if cmd.Flags().Changed("timeout") && !cmd.Flags().Changed("wait") {
+			return util.InvalidInputf("--timeout requires --wait")
+		}
+		if cmd.Flags().Changed("delete-uploads") && !cmd.Flags().Changed("wait") {
+			return util.InvalidInputf("--delete-uploads requires --wait")
+		}
+
 		destination := cmd.Flag("destination").Value.String()
 		if _, err := identifier.ParseIdentifier(destination); err != nil {
-			return fmt.Errorf("invalid destination specified: %s", destination)
+			return util.InvalidInputf("invalid destination specified: %s", destination)
 		}
 
 		// parse arg into model.Identifier
 		id, err := identifier.ParseIdentifier(args[0])
 		if err != nil {
-			return fmt.Errorf("invalid model specified: %s", args[0])
+			return util.InvalidInputf("invalid model specified: %s", args[0])
 		}
 
 		s := spinner.New(spinner.CharSets[21], 100*time.Millisecond)
@@ -64,28 +77,30 @@ var CreateCmd = &cobra.Command{
 			}
 		}
 
-		stdin, err := util.GetPipedArgs()
+		inputSchema, err := util.GetTrainingInputSchema(*version)
 		if err != nil {
-			return fmt.Errorf("failed to get stdin info: %w", err)
+			return fmt.Errorf("failed to get training input schema for version: %w", err)
 		}
 
-		separator := cmd.Flag("separator").Value.String()
-		inputs, err := util.ParseInputs(ctx, r8, args[1:], stdin, separator)
+		coercedInputs, uploads, err := prediction.ParseInputArgs(cmd, r8, args[1:], inputSchema)
 		if err != nil {
-			return fmt.Errorf("failed to parse inputs: %w", err)
+			return err
 		}
 
-		coercedInputs, err := util.CoerceTypes(inputs, nil)
+		webhook, err := prediction.WebhookFromFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to coerce inputs: %w", err)
+			return err
 		}
 
 		s.Start()
-		training, err := r8.CreateTraining(ctx, id.Owner, id.Name, version.ID, destination, coercedInputs, nil)
+		training, err := r8.CreateTraining(ctx, id.Owner, id.Name, version.ID, destination, coercedInputs, webhook)
+		s.Stop()
 		if err != nil {
 			return fmt.Errorf("failed to create training: %w", err)
 		}
-		s.Stop()
+
+		_ = history.RecordTraining(training)
+		defer func() { _ = history.RecordTraining(training) }()
 
 		url := fmt.Sprintf("https://replicate.com/p/%s", training.ID)
 		fmt.Printf("Training created: %s\n", url)
@@ -103,6 +118,36 @@ var CreateCmd = &cobra.Command{
 			return nil
 		}
 
+		if cmd.Flags().Changed("wait") {
+			if util.IsTTY() && !cmd.Flags().Changed("json") {
+				s.Suffix = " Waiting for training to finish"
+				s.Start()
+			}
+			waitCtx, cancel := prediction.WaitContext(cmd)
+			defer cancel()
+			err := waitForTraining(waitCtx, r8, training)
+			s.Stop()
+
+			switch {
+			case errors.Is(waitCtx.Err(), context.DeadlineExceeded):
+				return prediction.HandleTimeout(cmd, "training", training.ID, func(ctx context.Context) (*replicate.Prediction, error) {
+					canceled, err := r8.CancelTraining(ctx, training.ID)
+					return (*replicate.Prediction)(canceled), err
+				}, true)
+			case waitCtx.Err() != nil:
+				return prediction.HandleInterrupt(cmd, "training", training.ID, func(ctx context.Context) error {
+					_, err := r8.CancelTraining(ctx, training.ID)
+					return err
+				})
+			case err != nil:
+				return fmt.Errorf("failed to wait for training: %w", err)
+			}
+		}
+
+		if err := prediction.DeleteUploads(cmd, r8, (*replicate.Prediction)(training), uploads); err != nil {
+			return err
+		}
+
 		if cmd.Flags().Changed("json") || !util.IsTTY() {
 			b, err := json.Marshal(training)
 			if err != nil {
@@ -110,23 +155,59 @@ var CreateCmd = &cobra.Command{
 			}
 
 			fmt.Println(string(b))
-			return nil
+		} else if cmd.Flags().Changed("wait") && training.Status == replicate.Succeeded {
+			fmt.Println("✅ Succeeded")
+			if output, ok := training.Output.(map[string]interface{}); ok && output["version"] != nil {
+				fmt.Printf("Version: %s\n", output["version"])
+			}
 		}
 
-		return nil
+		return util.CheckTraining(training)
 	},
 }
 
+// waitForTraining polls a training until it finishes
+func waitForTraining(ctx context.Context, r8 *replicate.Client, training *replicate.Training) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for !training.Status.Terminated() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			updated, err := r8.GetTraining(ctx, training.ID)
+			if err != nil {
+				return err
+			}
+			*training = *updated
+		}
+	}
+
+	return nil
+}
+
 func init() {
 	AddCreateFlags(CreateCmd)
 }
 
 func AddCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("destination", "d", "", "Destination model for training")
+	_ = cmd.RegisterFlagCompletionFunc("destination", completion.Models)
 
 	cmd.Flags().Bool("json", false, "Emit JSON")
 	cmd.Flags().Bool("web", false, "View on web")
 	cmd.Flags().String("separator", "=", "Separator between input key and value")
+	prediction.AddInputDocumentFlags(cmd)
+	prediction.AddFileInputFlags(cmd)
+	cmd.Flags().Bool("delete-uploads", false, "Delete files uploaded for @file inputs once the training finishes. Requires --wait")
+
+	prediction.AddWebhookFlags(cmd)
 
 	cmd.MarkFlagsMutuallyExclusive("json", "web")
+
+	cmd.Flags().BoolP("wait", "w", false, "Wait for training to complete")
+	cmd.MarkFlagsMutuallyExclusive("wait", "web")
+	prediction.AddInterruptFlags(cmd)
+	prediction.AddTimeoutFlags(cmd)
 }
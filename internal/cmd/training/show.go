@@ -8,16 +8,22 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/completion"
+	"github.com/replicate/cli/internal/history"
 	"github.com/replicate/cli/internal/util"
 )
 
 var showCmd = &cobra.Command{
-	Use:     "show <id>",
-	Short:   "Show a training",
-	Args:    cobra.ExactArgs(1),
-	Aliases: []string{"view"},
+	Use:               "show <id>",
+	Short:             "Show a training",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.First(completion.Trainings),
+	Aliases:           []string{"view"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := args[0]
+		id, _, err := history.Resolve(args[0], history.KindTraining)
+		if err != nil {
+			return util.InvalidInput(fmt.Errorf("failed to parse training ID: %w", err))
+		}
 
 		if cmd.Flags().Changed("web") {
 			if util.IsTTY() {
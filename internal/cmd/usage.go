@@ -0,0 +1,450 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/pricing"
+	"github.com/replicate/cli/internal/util"
+)
+
+// usageGroupings are the ways predictions can be grouped
+var usageGroupings = []string{"model", "version", "deployment", "hardware"}
+
+var UsageCmd = &cobra.Command{
+	Use:   "usage [flags]",
+	Short: "Summarize the predict time and estimated cost of your predictions",
+	Long: `Summarize the predictions you created over a time window,
+grouped by model, version, deployment or hardware.
+
+Costs are estimated from predict time and the price of the hardware predictions ran on.
+Predictions made with a deployment run on the deployment's current hardware.
+Set prices, and the hardware other models run on, in a YAML or JSON file:
+
+  hardware:
+    gpu-a40-large: 0.000725  # USD per second
+  models:
+    stability-ai/sdxl: gpu-a40-large
+
+The file is read from --prices, or prices.yaml next to the CLI's config.`,
+	Example: `  replicate usage --since 7d
+  replicate usage --since 2024-01-01 --until 2024-02-01 --group-by deployment,hardware --csv > usage.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		now := time.Now().UTC()
+
+		sinceValue, _ := cmd.Flags().GetString("since")
+		since, err := parseTime(sinceValue, now)
+		if err != nil {
+			return util.InvalidInputf("invalid --since: %s", err)
+		}
+		until := now
+		if untilValue, _ := cmd.Flags().GetString("until"); untilValue != "" {
+			until, err = parseTime(untilValue, now)
+			if err != nil {
+				return util.InvalidInputf("invalid --until: %s", err)
+			}
+		}
+
+		groupBy, _ := cmd.Flags().GetStringSlice("group-by")
+		for _, g := range groupBy {
+			if !slices.Contains(usageGroupings, g) {
+				return util.InvalidInputf("invalid --group-by %q, expected one of %s", g, strings.Join(usageGroupings, ", "))
+			}
+		}
+
+		pricesPath, _ := cmd.Flags().GetString("prices")
+		prices, err := pricing.Load(pricesPath)
+		if err != nil {
+			return util.InvalidInput(err)
+		}
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		predictions, err := listPredictionsBetween(ctx, r8, since, until)
+		if err != nil {
+			return err
+		}
+
+		report := &usageReport{
+			Since:   since,
+			Until:   until,
+			GroupBy: groupBy,
+			Total:   &usageGroup{},
+		}
+		groups := map[usageKey]*usageGroup{}
+		hardware := deploymentHardware(ctx, r8)
+		for _, p := range predictions {
+			deployment := predictionDeployment(p)
+			sku := hardware(deployment)
+			if sku == "" {
+				sku = prices.Models[p.Model]
+			}
+
+			key := usageKey{}
+			for _, g := range groupBy {
+				switch g {
+				case "model":
+					key.Model = p.Model
+				case "version":
+					key.Version = p.Version
+				case "deployment":
+					key.Deployment = deployment
+				case "hardware":
+					key.Hardware = sku
+					if sku == "" {
+						key.Hardware = "unknown"
+					}
+				}
+			}
+
+			group, ok := groups[key]
+			if !ok {
+				group = &usageGroup{usageKey: key}
+				groups[key] = group
+				report.Groups = append(report.Groups, group)
+			}
+
+			group.add(p, sku, prices)
+			report.Total.add(p, sku, prices)
+		}
+
+		for _, group := range append(report.Groups, report.Total) {
+			group.PredictTime = util.Summarize(group.predictTimes)
+		}
+		sort.SliceStable(report.Groups, func(i, j int) bool {
+			a, b := report.Groups[i], report.Groups[j]
+			if a.EstimatedCost != b.EstimatedCost {
+				return a.EstimatedCost > b.EstimatedCost
+			}
+			return a.Count > b.Count
+		})
+
+		switch {
+		case cmd.Flags().Changed("csv"):
+			return writeUsageCSV(report)
+		case cmd.Flags().Changed("json") || !util.IsTTY():
+			bytes, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal usage: %w", err)
+			}
+			fmt.Println(string(bytes))
+			return nil
+		default:
+			return writeUsageTable(report)
+		}
+	},
+}
+
+type usageReport struct {
+	Since   time.Time     `json:"since"`
+	Until   time.Time     `json:"until"`
+	GroupBy []string      `json:"group_by"`
+	Groups  []*usageGroup `json:"groups"`
+	Total   *usageGroup   `json:"total"`
+}
+
+type usageKey struct {
+	Model      string `json:"model,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Deployment string `json:"deployment,omitempty"`
+	Hardware   string `json:"hardware,omitempty"`
+}
+
+// get returns the value of a key for a grouping
+func (k usageKey) get(grouping string) string {
+	switch grouping {
+	case "model":
+		return k.Model
+	case "version":
+		return k.Version
+	case "deployment":
+		return k.Deployment
+	default:
+		return k.Hardware
+	}
+}
+
+type usageGroup struct {
+	usageKey
+	Count         int        `json:"count"`
+	Succeeded     int        `json:"succeeded"`
+	Failed        int        `json:"failed"`
+	Canceled      int        `json:"canceled"`
+	PredictTime   util.Stats `json:"predict_time"`
+	EstimatedCost float64    `json:"estimated_cost"`
+
+	// Unpriced is how many predictions ran on hardware without a price,
+	// which aren't included in the estimated cost
+	Unpriced int `json:"unpriced"`
+
+	predictTimes []float64
+}
+
+func (g *usageGroup) add(p replicate.Prediction, sku string, prices *pricing.Table) {
+	g.Count++
+	switch p.Status {
+	case replicate.Succeeded:
+		g.Succeeded++
+	case replicate.Failed:
+		g.Failed++
+	case replicate.Canceled:
+		g.Canceled++
+	}
+
+	if p.Metrics == nil || p.Metrics.PredictTime == nil {
+		return
+	}
+
+	seconds := *p.Metrics.PredictTime
+	g.predictTimes = append(g.predictTimes, seconds)
+	if cost, ok := prices.Cost(sku, seconds); ok {
+		g.EstimatedCost += cost
+	} else {
+		g.Unpriced++
+	}
+}
+
+// listPredictionsBetween returns the predictions created in a time window,
+// fetching pages until they're older than it
+func listPredictionsBetween(ctx context.Context, r8 *replicate.Client, since time.Time, until time.Time) ([]replicate.Prediction, error) {
+	page, err := r8.ListPredictions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list predictions: %w", err)
+	}
+
+	predictions := []replicate.Prediction{}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Predictions are listed newest first
+	results, errs := replicate.Paginate(ctx, r8, page)
+	for results != nil || errs != nil {
+		select {
+		case batch, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+
+			for _, p := range batch {
+				createdAt, err := time.Parse(time.RFC3339Nano, p.CreatedAt)
+				if err != nil {
+					continue
+				}
+				if createdAt.Before(since) {
+					return predictions, nil
+				}
+				if createdAt.Before(until) {
+					predictions = append(predictions, p)
+				}
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list predictions: %w", err)
+			}
+		}
+	}
+
+	return predictions, nil
+}
+
+// predictionDeployment returns the deployment that created a prediction, if any,
+// which replicate-go doesn't decode
+func predictionDeployment(p replicate.Prediction) string {
+	var fields struct {
+		Deployment string `json:"deployment"`
+	}
+	_ = json.Unmarshal(p.RawJSON(), &fields)
+	return fields.Deployment
+}
+
+// deploymentHardware returns a function that looks up the hardware of deployments,
+// getting each deployment once
+func deploymentHardware(ctx context.Context, r8 *replicate.Client) func(name string) string {
+	skus := map[string]string{}
+	return func(name string) string {
+		if name == "" {
+			return ""
+		}
+		if sku, ok := skus[name]; ok {
+			return sku
+		}
+
+		if id, err := identifier.ParseIdentifier(name); err == nil {
+			if d, err := r8.GetDeployment(ctx, id.Owner, id.Name); err == nil {
+				skus[name] = d.CurrentRelease.Configuration.Hardware
+			}
+		}
+		return skus[name]
+	}
+}
+
+// parseTime parses a time like 2024-01-01 or 2024-01-01T12:00:00Z,
+// or a duration before now like 24h or 7d
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("expected a date like 2024-01-01 or a duration like 7d, got %q", value)
+}
+
+func writeUsageTable(report *usageReport) error {
+	fmt.Printf("Predictions created from %s to %s\n\n", report.Since.Format(time.RFC3339), report.Until.Format(time.RFC3339))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	header := []string{}
+	for _, g := range report.GroupBy {
+		header = append(header, strings.ToUpper(g))
+	}
+	header = append(header, "COUNT", "FAILED", "TOTAL", "P50", "P90", "P99", "EST. COST")
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	row := func(keys []string, g *usageGroup) {
+		cells := append(keys,
+			strconv.Itoa(g.Count),
+			strconv.Itoa(g.Failed),
+			formatSeconds(g.PredictTime.Total),
+			formatSeconds(g.PredictTime.P50),
+			formatSeconds(g.PredictTime.P90),
+			formatSeconds(g.PredictTime.P99),
+			formatCost(g),
+		)
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	for _, g := range report.Groups {
+		keys := []string{}
+		for _, grouping := range report.GroupBy {
+			value := g.get(grouping)
+			if value == "" {
+				value = "-"
+			}
+			keys = append(keys, value)
+		}
+		row(keys, g)
+	}
+
+	totalKeys := make([]string, len(report.GroupBy))
+	if len(totalKeys) > 0 {
+		totalKeys[0] = "Total"
+	}
+	row(totalKeys, report.Total)
+
+	return w.Flush()
+}
+
+func writeUsageCSV(report *usageReport) error {
+	w := csv.NewWriter(os.Stdout)
+
+	header := append([]string{}, report.GroupBy...)
+	header = append(header, "count", "succeeded", "failed", "canceled",
+		"total_predict_time", "mean_predict_time", "p50_predict_time", "p90_predict_time", "p99_predict_time",
+		"estimated_cost_usd", "unpriced")
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	number := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	for _, g := range report.Groups {
+		record := []string{}
+		for _, grouping := range report.GroupBy {
+			record = append(record, g.get(grouping))
+		}
+		record = append(record,
+			strconv.Itoa(g.Count),
+			strconv.Itoa(g.Succeeded),
+			strconv.Itoa(g.Failed),
+			strconv.Itoa(g.Canceled),
+			number(g.PredictTime.Total),
+			number(g.PredictTime.Mean),
+			number(g.PredictTime.P50),
+			number(g.PredictTime.P90),
+			number(g.PredictTime.P99),
+			strconv.FormatFloat(g.EstimatedCost, 'f', 6, 64),
+			strconv.Itoa(g.Unpriced),
+		)
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.2fs", seconds)
+}
+
+// formatCost formats the estimated cost of a group,
+// noting predictions that aren't included because their hardware has no price
+func formatCost(g *usageGroup) string {
+	if g.Unpriced > 0 && g.Unpriced == len(g.predictTimes) {
+		return "-"
+	}
+
+	cost := fmt.Sprintf("$%.4f", g.EstimatedCost)
+	if g.Unpriced > 0 {
+		cost += fmt.Sprintf(" (%d unpriced)", g.Unpriced)
+	}
+	return cost
+}
+
+func init() {
+	addUsageFlags(UsageCmd)
+}
+
+func addUsageFlags(cmd *cobra.Command) {
+	cmd.Flags().String("since", "24h", "Start of the time window, as a date like 2024-01-01 or a duration before now like 7d")
+	cmd.Flags().String("until", "", "End of the time window, as a date or a duration before now, defaults to now")
+	cmd.Flags().StringSlice("group-by", []string{"model"}, "Group predictions by model, version, deployment or hardware")
+	cmd.Flags().String("prices", "", "YAML or JSON file of hardware prices in USD per second, and the hardware models run on")
+
+	cmd.Flags().Bool("json", false, "Emit JSON")
+	cmd.Flags().Bool("csv", false, "Emit CSV")
+	cmd.MarkFlagsMutuallyExclusive("json", "csv")
+}
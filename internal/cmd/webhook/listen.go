@@ -0,0 +1,280 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/util"
+)
+
+const maxWebhookSize = 32 << 20
+
+var (
+	verifiedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	rejectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	payloadStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).PaddingLeft(2)
+)
+
+// event is a webhook request received by the listener
+type event struct {
+	ID        string          `json:"webhook_id"`
+	Timestamp string          `json:"webhook_timestamp"`
+	Verified  bool            `json:"verified"`
+	Error     string          `json:"error,omitempty"`
+	Forwarded *int            `json:"forwarded_status,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// listener receives webhooks and optionally forwards them
+type listener struct {
+	secret    string
+	verify    bool
+	tolerance time.Duration
+	forwardTo string
+	saveDir   string
+	json      bool
+
+	client *http.Client
+}
+
+func (l *listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	e := event{
+		ID:        r.Header.Get("webhook-id"),
+		Timestamp: r.Header.Get("webhook-timestamp"),
+		Payload:   body,
+	}
+	if !json.Valid(body) {
+		e.Payload, _ = json.Marshal(string(body))
+	}
+
+	if l.verify {
+		if err := util.VerifyWebhook(r.Header.Clone(), body, l.secret, l.tolerance); err != nil {
+			e.Error = err.Error()
+			l.print(e)
+			http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+			return
+		}
+		e.Verified = true
+	}
+
+	if l.saveDir != "" {
+		if err := saveWebhook(l.saveDir, r.Header, body); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to save webhook: %s\n", err)
+		}
+	}
+
+	status := http.StatusOK
+	var response []byte
+	if l.forwardTo != "" {
+		status, response, err = l.forward(r, body)
+		if err != nil {
+			e.Error = err.Error()
+			status = http.StatusBadGateway
+		} else {
+			e.Forwarded = &status
+		}
+	}
+
+	l.print(e)
+
+	w.WriteHeader(status)
+	_, _ = w.Write(response)
+}
+
+// forward sends a webhook on to another URL with its signature headers,
+// and returns the response
+func (l *listener) forward(r *http.Request, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, l.forwardTo, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	for _, key := range util.WebhookHeaders {
+		req.Header.Set(key, r.Header.Get(key))
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to forward webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookSize))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read forwarded response: %w", err)
+	}
+
+	return resp.StatusCode, response, nil
+}
+
+func (l *listener) print(e event) {
+	if l.json {
+		b, _ := json.Marshal(e)
+		fmt.Println(string(b))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(time.Now().Format("15:04:05") + " ")
+
+	switch {
+	case e.Verified:
+		b.WriteString(verifiedStyle.Render("✓ verified") + " ")
+	case !l.verify:
+		b.WriteString("- unverified ")
+	default:
+		b.WriteString(rejectedStyle.Render("✗ rejected") + " ")
+	}
+
+	var prediction replicate.Prediction
+	if err := json.Unmarshal(e.Payload, &prediction); err == nil && prediction.ID != "" {
+		fmt.Fprintf(&b, "%s %s %s", prediction.ID, util.StatusSymbol(prediction.Status), prediction.Status)
+		if prediction.Model != "" {
+			fmt.Fprintf(&b, " %s", prediction.Model)
+		}
+	} else if e.ID != "" {
+		b.WriteString(e.ID)
+	}
+
+	if e.Forwarded != nil {
+		fmt.Fprintf(&b, " → %d", *e.Forwarded)
+	}
+	if e.Error != "" {
+		b.WriteString(" " + rejectedStyle.Render(e.Error))
+	}
+
+	fmt.Println(b.String())
+
+	var indented bytes.Buffer
+	if json.Indent(&indented, e.Payload, "", "  ") == nil {
+		fmt.Println(payloadStyle.Render(indented.String()))
+	}
+}
+
+// saveWebhook writes a webhook's body and signature headers to a directory,
+// so it can be checked later with "webhook verify"
+func saveWebhook(dir string, header http.Header, body []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	name := header.Get("webhook-id")
+	if name == "" {
+		name = fmt.Sprintf("webhook-%d", time.Now().UnixNano())
+	}
+	name = filepath.Base(name)
+
+	var headers strings.Builder
+	for _, key := range util.WebhookHeaders {
+		fmt.Fprintf(&headers, "%s: %s\n", key, header.Get(key))
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), body, 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, name+".headers"), []byte(headers.String()), 0o644)
+}
+
+var listenCmd = &cobra.Command{
+	Use:   "listen [flags]",
+	Short: "Receive webhooks on a local port",
+	Long: `Receive webhooks on a local port, verifying their signatures and printing each event.
+
+Use a tunnel like ngrok to expose the port to Replicate,
+and pass its URL to "replicate run --webhook".`,
+	Example: `  replicate webhook listen --port 8080
+  replicate webhook listen --port 8080 --forward-to http://localhost:3000/webhooks/replicate`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		l := &listener{
+			verify: !cmd.Flags().Changed("skip-verify"),
+			json:   cmd.Flags().Changed("json") || !util.IsTTY(),
+			client: http.DefaultClient,
+		}
+		l.tolerance, _ = cmd.Flags().GetDuration("tolerance")
+		l.forwardTo, _ = cmd.Flags().GetString("forward-to")
+		l.saveDir, _ = cmd.Flags().GetString("save-dir")
+
+		if l.verify {
+			secret, err := getSecret(cmd)
+			if err != nil {
+				return err
+			}
+			l.secret = secret
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprint(port)))
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+
+		server := &http.Server{Handler: l, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Fprintf(os.Stderr, "Listening for webhooks on http://%s\n", ln.Addr())
+		if l.forwardTo != "" {
+			fmt.Fprintf(os.Stderr, "Forwarding to %s\n", l.forwardTo)
+		}
+
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addListenFlags(listenCmd)
+}
+
+func addListenFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("port", "p", 8080, "Port to listen on")
+	cmd.Flags().String("host", "127.0.0.1", "Host to listen on")
+	cmd.Flags().String("forward-to", "", "URL to forward verified webhooks to")
+	cmd.Flags().String("save-dir", "", "Directory to save each webhook's body and headers to")
+	cmd.Flags().Duration("tolerance", 5*time.Minute, "Maximum age of a webhook's timestamp, or 0 to accept any")
+	cmd.Flags().Bool("skip-verify", false, "Don't verify webhook signatures")
+	cmd.Flags().Bool("json", false, "Emit JSON")
+	addSecretFlag(cmd)
+
+	cmd.MarkFlagsMutuallyExclusive("skip-verify", "secret")
+}
@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/util"
+)
+
+var testSecret = "whsec_" + base64.StdEncoding.EncodeToString([]byte("test-secret-key"))
+
+// postWebhook sends a webhook signed for signedBody, with body as its content
+func postWebhook(t *testing.T, url string, id string, signedBody []byte, body []byte) *http.Response {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature, err := util.SignWebhook(id, timestamp, signedBody, testSecret)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("webhook-id", id)
+	req.Header.Set("webhook-timestamp", timestamp)
+	req.Header.Set("webhook-signature", signature)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func TestListener(t *testing.T) {
+	var forwarded []*http.Request
+	var forwardedBodies [][]byte
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		forwarded = append(forwarded, r)
+		forwardedBodies = append(forwardedBodies, body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	dir := t.TempDir()
+	server := httptest.NewServer(&listener{
+		secret:    testSecret,
+		verify:    true,
+		tolerance: time.Minute,
+		forwardTo: target.URL,
+		saveDir:   dir,
+		json:      true,
+		client:    http.DefaultClient,
+	})
+	defer server.Close()
+
+	body := []byte(`{"id":"abc","status":"succeeded"}`)
+
+	// A signed webhook is forwarded with its signature, and saved
+	resp := postWebhook(t, server.URL, "msg_1", body, body)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	response, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "ok", string(response))
+
+	if assert.Len(t, forwarded, 1) {
+		assert.Equal(t, body, forwardedBodies[0])
+		assert.Equal(t, "msg_1", forwarded[0].Header.Get("webhook-id"))
+		assert.NotEmpty(t, forwarded[0].Header.Get("webhook-signature"))
+		assert.NoError(t, util.VerifyWebhook(forwarded[0].Header, forwardedBodies[0], testSecret, time.Minute))
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, "msg_1.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, body, saved)
+	headers, err := os.ReadFile(filepath.Join(dir, "msg_1.headers"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(headers), "webhook-id: msg_1\n")
+	assert.Contains(t, string(headers), "webhook-signature: v1,")
+
+	// A tampered webhook is rejected, and neither forwarded nor saved
+	resp = postWebhook(t, server.URL, "msg_2", body, []byte(`{"id":"abc","status":"failed"}`))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Len(t, forwarded, 1)
+	assert.NoFileExists(t, filepath.Join(dir, "msg_2.json"))
+
+	resp, err = http.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestListenerSkipVerify(t *testing.T) {
+	server := httptest.NewServer(&listener{json: true, client: http.DefaultClient})
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader([]byte(`{"id":"abc"}`)))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestListenerForwardError(t *testing.T) {
+	target := httptest.NewServer(http.NotFoundHandler())
+	target.Close()
+
+	server := httptest.NewServer(&listener{json: true, forwardTo: target.URL, client: http.DefaultClient})
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader([]byte(`{"id":"abc"}`)))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestSaveWebhook(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "webhooks")
+
+	// IDs can't escape the directory
+	header := http.Header{}
+	header.Set("webhook-id", "../msg_1")
+	assert.NoError(t, saveWebhook(dir, header, []byte(`{}`)))
+	assert.FileExists(t, filepath.Join(dir, "msg_1.json"))
+	assert.FileExists(t, filepath.Join(dir, "msg_1.headers"))
+
+	// Webhooks without IDs get a name of their own
+	assert.NoError(t, saveWebhook(dir, http.Header{}, []byte(`{}`)))
+	matches, err := filepath.Glob(filepath.Join(dir, "webhook-*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+)
+
+var RootCmd = &cobra.Command{
+	Use:     "webhook [subcommand]",
+	Short:   "Receive and verify webhooks",
+	Aliases: []string{"webhooks", "wh"},
+}
+
+func init() {
+	RootCmd.AddGroup(&cobra.Group{
+		ID:    "subcommand",
+		Title: "Subcommands:",
+	})
+	for _, cmd := range []*cobra.Command{
+		listenCmd,
+		verifyCmd,
+	} {
+		RootCmd.AddCommand(cmd)
+		cmd.GroupID = "subcommand"
+	}
+}
+
+// getSecret returns the webhook signing secret from the secret flag,
+// the REPLICATE_WEBHOOK_SECRET environment variable, or the account's default secret
+func getSecret(cmd *cobra.Command) (string, error) {
+	if secret, _ := cmd.Flags().GetString("secret"); secret != "" {
+		return secret, nil
+	}
+
+	if secret := os.Getenv("REPLICATE_WEBHOOK_SECRET"); secret != "" {
+		return secret, nil
+	}
+
+	r8, err := client.NewClient()
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := r8.GetDefaultWebhookSecret(cmd.Context())
+	if err != nil {
+		return "", fmt.Errorf("failed to get webhook signing secret: %w", err)
+	}
+
+	return secret.Key, nil
+}
+
+func addSecretFlag(cmd *cobra.Command) {
+	cmd.Flags().String("secret", "", "Webhook signing secret, defaults to $REPLICATE_WEBHOOK_SECRET or your account's secret")
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/util"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <payload-file|-> [flags]",
+	Short: "Verify the signature of a saved webhook",
+	Long: `Verify the signature of a saved webhook body against its headers.
+
+Headers can be passed as flags, or as a file with lines like "webhook-id: msg_...",
+like the ones written by "replicate webhook listen --save-dir".`,
+	Example: `  replicate webhook verify msg_2b4Jx.json --headers msg_2b4Jx.headers --secret whsec_...
+  cat payload.json | replicate webhook verify - --id msg_2b4Jx --timestamp 1700000000 --signature v1,...`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var body []byte
+		var err error
+		if args[0] == "-" {
+			body, err = io.ReadAll(os.Stdin)
+		} else {
+			body, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read payload: %w", err)
+		}
+
+		header := http.Header{}
+		if path, _ := cmd.Flags().GetString("headers"); path != "" {
+			header, err = readHeaders(path)
+			if err != nil {
+				return fmt.Errorf("failed to read headers: %w", err)
+			}
+		}
+		for _, key := range util.WebhookHeaders {
+			flag := key[len("webhook-"):]
+			if value, _ := cmd.Flags().GetString(flag); value != "" {
+				header.Set(key, value)
+			}
+		}
+
+		secret, err := getSecret(cmd)
+		if err != nil {
+			return err
+		}
+
+		tolerance, _ := cmd.Flags().GetDuration("tolerance")
+		if err := util.VerifyWebhook(header, body, secret, tolerance); err != nil {
+			return fmt.Errorf("webhook is invalid: %w", err)
+		}
+
+		fmt.Println("✓ Webhook signature is valid")
+
+		return nil
+	},
+}
+
+// readHeaders reads HTTP headers from a file with a header on each line
+func readHeaders(path string) (http.Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header, err := textproto.NewReader(bufio.NewReader(f)).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return http.Header(header), nil
+}
+
+func init() {
+	addVerifyFlags(verifyCmd)
+}
+
+func addVerifyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("headers", "", "File with the webhook's headers")
+	cmd.Flags().String("id", "", "Value of the webhook-id header")
+	cmd.Flags().String("timestamp", "", "Value of the webhook-timestamp header")
+	cmd.Flags().String("signature", "", "Value of the webhook-signature header")
+	cmd.Flags().Duration("tolerance", 0, "Maximum age of the webhook's timestamp, or 0 to accept any")
+	addSecretFlag(cmd)
+}
@@ -0,0 +1,142 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/replicate/replicate-go"
+
+	"github.com/replicate/cli/internal/util"
+	wf "github.com/replicate/cli/internal/workflow"
+)
+
+var (
+	stepStyle   = lipgloss.NewStyle().Bold(true)
+	detailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+type stepUpdateMsg wf.StepResult
+
+type doneMsg struct{}
+
+// progressModel shows the steps of a workflow as they run,
+// indented by how deep they are in the graph of dependencies
+type progressModel struct {
+	workflow *wf.Workflow
+	cancel   context.CancelFunc
+
+	steps   []*wf.Step
+	depths  map[string]int
+	results map[string]wf.StepResult
+}
+
+func newProgressModel(w *wf.Workflow, cancel context.CancelFunc) progressModel {
+	steps, _ := w.Order()
+
+	depths := map[string]int{}
+	for _, step := range steps {
+		for _, dep := range step.Dependencies() {
+			if depths[dep]+1 > depths[step.ID] {
+				depths[step.ID] = depths[dep] + 1
+			}
+		}
+	}
+
+	return progressModel{
+		workflow: w,
+		cancel:   cancel,
+		steps:    steps,
+		depths:   depths,
+		results:  map[string]wf.StepResult{},
+	}
+}
+
+func (m progressModel) Init() tea.Cmd { return nil }
+
+func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case stepUpdateMsg:
+		m.results[msg.ID] = wf.StepResult(msg)
+	case doneMsg:
+		return m, tea.Quit
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			// Keep showing progress until the running steps stop
+			m.cancel()
+		}
+	}
+	return m, nil
+}
+
+func (m progressModel) View() string {
+	var b strings.Builder
+
+	if m.workflow.Name != "" {
+		b.WriteString(stepStyle.Render(m.workflow.Name) + "\n")
+	}
+
+	for _, step := range m.steps {
+		result, ok := m.results[step.ID]
+		if !ok {
+			result = wf.StepResult{ID: step.ID, Status: wf.StepPending}
+		}
+
+		target := step.Model
+		if step.Deployment != "" {
+			target = "deployment " + step.Deployment
+		}
+
+		details := []string{target}
+		if step.ForEach != "" && result.Predictions != nil {
+			finished := 0
+			for _, p := range result.Predictions {
+				if p != nil && p.Status.Terminated() {
+					finished++
+				}
+			}
+			details = append(details, fmt.Sprintf("%d/%d", finished, len(result.Predictions)))
+		}
+		if deps := step.Dependencies(); len(deps) > 0 {
+			details = append(details, "← "+strings.Join(deps, ", "))
+		}
+		if result.Attempts > 1 {
+			details = append(details, fmt.Sprintf("%d attempts", result.Attempts))
+		}
+
+		fmt.Fprintf(&b, "%s%s %s %s\n",
+			strings.Repeat("  ", m.depths[step.ID]),
+			stepSymbol(result.Status),
+			stepStyle.Render(step.ID),
+			detailStyle.Render(strings.Join(details, " · ")),
+		)
+
+		if result.Error != "" && result.Status == wf.StepFailed {
+			fmt.Fprintf(&b, "%s   %s\n", strings.Repeat("  ", m.depths[step.ID]), errorStyle.Render(result.Error))
+		}
+	}
+
+	return b.String()
+}
+
+// stepSymbol returns the symbol for a step's status,
+// like the symbols for prediction statuses
+func stepSymbol(status wf.StepStatus) string {
+	switch status {
+	case wf.StepPending:
+		return util.StatusSymbol(replicate.Starting)
+	case wf.StepRunning:
+		return util.StatusSymbol(replicate.Processing)
+	case wf.StepSucceeded:
+		return util.StatusSymbol(replicate.Succeeded)
+	case wf.StepFailed:
+		return util.StatusSymbol(replicate.Failed)
+	case wf.StepSkipped:
+		return util.StatusSymbol(replicate.Canceled)
+	default:
+		return string(status)
+	}
+}
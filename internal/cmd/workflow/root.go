@@ -0,0 +1,24 @@
+package workflow
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var RootCmd = &cobra.Command{
+	Use:     "workflow [subcommand]",
+	Short:   "Run workflows of predictions",
+	Aliases: []string{"workflows", "wf"},
+}
+
+func init() {
+	RootCmd.AddGroup(&cobra.Group{
+		ID:    "subcommand",
+		Title: "Subcommands:",
+	})
+	for _, cmd := range []*cobra.Command{
+		runCmd,
+	} {
+		RootCmd.AddCommand(cmd)
+		cmd.GroupID = "subcommand"
+	}
+}
@@ -0,0 +1,321 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/cmd/deployment"
+	"github.com/replicate/cli/internal/cmd/prediction"
+	"github.com/replicate/cli/internal/history"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/util"
+	wf "github.com/replicate/cli/internal/workflow"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <path> [input=value] ... [flags]",
+	Short: "Run a workflow",
+	Long: `Run a workflow of predictions defined in a YAML file.
+
+Each step runs a model or a deployment. Inputs can refer to the workflow's
+inputs and to earlier steps, like "{{ inputs.prompt }}" or
+"{{ steps.generate.output[0] }}". Steps run as soon as the steps they
+refer to have finished. A step with "for_each" creates a prediction for
+each item of a list, "if" skips a step when its expression is false,
+and "retries" tries failed predictions again.
+
+A manifest of the run is written to manifest.json in the output directory.`,
+	Example: `  replicate workflow run flow.yaml prompt="a corgi"
+  replicate workflow run flow.yaml --save -o ./corgis`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+
+		w, err := wf.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		r8, err := client.NewClient()
+		if err != nil {
+			return err
+		}
+
+		separator := cmd.Flag("separator").Value.String()
+		parsed, err := util.ParseInputsWithOptions(ctx, r8, args[1:], "", util.InputOptions{Separator: separator})
+		if err != nil {
+			return util.InvalidInput(fmt.Errorf("failed to parse inputs: %w", err))
+		}
+
+		inputs, err := util.CoerceInputs(parsed, nil)
+		if err != nil {
+			return util.InvalidInput(fmt.Errorf("failed to coerce inputs: %w", err))
+		}
+
+		dirname, _ := cmd.Flags().GetString("output-directory")
+		if dirname == "" {
+			name := w.Name
+			if name == "" {
+				name = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+			}
+			dirname = fmt.Sprintf("./%s-%s", name, time.Now().Format("20060102-150405"))
+		}
+
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		runner := &wf.Runner{
+			Workflow:    w,
+			Run:         newStepFunc(r8, w),
+			Concurrency: concurrency,
+			RetryDelay:  2 * time.Second,
+		}
+
+		var manifest *wf.Manifest
+		var runErr error
+
+		if util.IsTTY() && !cmd.Flags().Changed("json") {
+			p := tea.NewProgram(newProgressModel(w, cancel))
+			runner.OnUpdate = func(result wf.StepResult) {
+				p.Send(stepUpdateMsg(result))
+			}
+
+			finished := make(chan struct{})
+			go func() {
+				defer close(finished)
+				manifest, runErr = runner.Execute(ctx, inputs)
+				p.Send(doneMsg{})
+			}()
+
+			_, err := p.Run()
+			if err != nil {
+				cancel()
+			}
+			<-finished
+			if err != nil {
+				return err
+			}
+		} else {
+			var mu sync.Mutex
+			statuses := map[string]wf.StepStatus{}
+			runner.OnUpdate = func(result wf.StepResult) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				if result.Status != statuses[result.ID] && result.Status != wf.StepPending {
+					fmt.Fprintf(os.Stderr, "%s %s %s\n", stepSymbol(result.Status), result.ID, result.Status)
+				}
+				statuses[result.ID] = result.Status
+			}
+			manifest, runErr = runner.Execute(ctx, inputs)
+		}
+
+		if manifest == nil {
+			return runErr
+		}
+
+		dir, err := filepath.Abs(dirname)
+		if err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		files := map[string][]util.DownloadedFile{}
+		if cmd.Flags().Changed("save") {
+			files, err = saveOutputs(ctx, manifest, dir)
+			if err != nil {
+				return err
+			}
+		}
+
+		bytes, err := writeManifest(manifest, files, dir)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("json") || !util.IsTTY() {
+			fmt.Println(string(bytes))
+		} else {
+			fmt.Printf("Wrote manifest to %s\n", filepath.Join(dirname, "manifest.json"))
+		}
+
+		return runErr
+	},
+}
+
+// newStepFunc returns a function that creates and waits for the prediction of a step.
+// @file references in inputs are uploaded, and inputs are coerced to the types in
+// the schema of the step's model version.
+func newStepFunc(r8 *replicate.Client, w *wf.Workflow) wf.StepFunc {
+	var mu sync.Mutex
+	targets := map[string]*stepTarget{}
+
+	return func(ctx context.Context, step *wf.Step, inputs map[string]interface{}) (*replicate.Prediction, error) {
+		resolved, err := util.ParseInputDocument(ctx, r8, inputs, w.Dir, util.InputOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		name := step.Model
+		if step.Deployment != "" {
+			name = step.Deployment
+		}
+		id, err := identifier.ParseIdentifier(name)
+		if err != nil {
+			return nil, util.InvalidInputf("invalid model or deployment specified: %s", name)
+		}
+
+		mu.Lock()
+		t, ok := targets[step.ID]
+		mu.Unlock()
+
+		if !ok {
+			t, err = getStepTarget(ctx, r8, step, id)
+			if err != nil {
+				return nil, err
+			}
+
+			mu.Lock()
+			targets[step.ID] = t
+			mu.Unlock()
+		}
+
+		coerced, err := util.CoerceInputs(resolved, t.inputSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to coerce inputs: %w", err)
+		}
+
+		var p *replicate.Prediction
+		if step.Deployment != "" {
+			p, err = r8.CreatePredictionWithDeployment(ctx, id.Owner, id.Name, coerced, nil, false)
+		} else {
+			p, err = prediction.CreatePrediction(ctx, r8, id, t.version, coerced, nil, false)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prediction: %w", err)
+		}
+		_ = history.RecordPrediction(p)
+
+		if !p.Status.Terminated() {
+			err := r8.Wait(ctx, p)
+			_ = history.RecordPrediction(p)
+			if err != nil {
+				return p, fmt.Errorf("failed to wait for prediction: %w", err)
+			}
+		}
+
+		return p, nil
+	}
+}
+
+// stepTarget is the model version and input schema a step runs with
+type stepTarget struct {
+	version     *replicate.ModelVersion
+	inputSchema *openapi3.Schema
+}
+
+func getStepTarget(ctx context.Context, r8 *replicate.Client, step *wf.Step, id *identifier.Identifier) (*stepTarget, error) {
+	t := &stepTarget{}
+
+	if step.Deployment != "" {
+		d, err := r8.GetDeployment(ctx, id.Owner, id.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		t.inputSchema, _, err = deployment.GetReleaseSchemas(ctx, r8, d)
+		if err != nil {
+			return nil, err
+		}
+
+		return t, nil
+	}
+
+	t.version = prediction.GetModelVersion(ctx, r8, id)
+	if t.version != nil {
+		var err error
+		t.inputSchema, _, err = util.GetSchemas(*t.version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get input schema for version: %w", err)
+		}
+	}
+
+	return t, nil
+}
+
+// saveOutputs downloads the outputs of each step's predictions to a directory named after the step,
+// with a subdirectory for each item of a for_each step
+func saveOutputs(ctx context.Context, manifest *wf.Manifest, dir string) (map[string][]util.DownloadedFile, error) {
+	files := map[string][]util.DownloadedFile{}
+	downloader := &util.Downloader{}
+
+	for _, result := range manifest.Steps {
+		for i, p := range result.Predictions {
+			if p == nil || p.Status != replicate.Succeeded || p.Output == nil {
+				continue
+			}
+
+			stepDir := filepath.Join(dir, result.ID)
+			if len(result.Predictions) > 1 {
+				stepDir = filepath.Join(stepDir, fmt.Sprint(i))
+			}
+
+			saved, err := downloader.Download(ctx, *p, stepDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to save output of step %s: %w", result.ID, err)
+			}
+
+			for _, file := range saved {
+				if rel, err := filepath.Rel(dir, file.Path); err == nil {
+					file.Path = rel
+				}
+				files[result.ID] = append(files[result.ID], file)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// writeManifest writes manifest.json to dir, with the run and the saved files
+func writeManifest(manifest *wf.Manifest, files map[string][]util.DownloadedFile, dir string) ([]byte, error) {
+	bytes, err := json.MarshalIndent(struct {
+		*wf.Manifest
+		Files map[string][]util.DownloadedFile `json:"files,omitempty"`
+	}{manifest, files}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), bytes, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest.json: %w", err)
+	}
+
+	return bytes, nil
+}
+
+func init() {
+	addRunFlags(runCmd)
+}
+
+func addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().String("separator", "=", "Separator between input key and value")
+	cmd.Flags().Int("concurrency", 4, "Maximum number of predictions to run at once, or 0 for no limit")
+	cmd.Flags().Bool("save", false, "Save the outputs of each step to the output directory")
+	cmd.Flags().StringP("output-directory", "o", "", "Output directory for the manifest and saved outputs, defaults to ./{name}-{time}")
+	cmd.Flags().Bool("json", false, "Emit JSON")
+}
@@ -0,0 +1,120 @@
+package cog
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Client runs predictions with a Cog server, like one started with "cog run"
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for the Cog server at baseURL, like http://localhost:5000
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// GetVersion returns a model version with the server's OpenAPI schema,
+// so it can be used like the version of a hosted model
+func (c *Client) GetVersion(ctx context.Context) (*replicate.ModelVersion, error) {
+	schema := map[string]interface{}{}
+	if err := c.do(ctx, http.MethodGet, "/openapi.json", nil, &schema); err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	return &replicate.ModelVersion{
+		ID:            "local",
+		OpenAPISchema: schema,
+	}, nil
+}
+
+// Predict runs a prediction and waits for it to finish
+func (c *Client) Predict(ctx context.Context, input replicate.PredictionInput) (*replicate.Prediction, error) {
+	id, err := newPredictionID()
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"id":    id,
+		"input": input,
+	}
+
+	prediction := &replicate.Prediction{}
+	if err := c.do(ctx, http.MethodPost, "/predictions", body, prediction); err != nil {
+		return nil, fmt.Errorf("failed to run prediction: %w", err)
+	}
+
+	if prediction.ID == "" {
+		prediction.ID = id
+	}
+	if prediction.Input == nil {
+		prediction.Input = input
+	}
+
+	return prediction, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body interface{}, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// newPredictionID returns a random ID for a local prediction
+func newPredictionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate prediction ID: %w", err)
+	}
+
+	return "local-" + hex.EncodeToString(b), nil
+}
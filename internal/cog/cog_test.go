@@ -0,0 +1,61 @@
+package cog_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/replicate/replicate-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/cog"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/openapi.json":
+			_, _ = w.Write([]byte(`{"components":{"schemas":{"Input":{"type":"object"}}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/predictions":
+			var body struct {
+				ID    string                 `json:"id"`
+				Input map[string]interface{} `json:"input"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.NotEmpty(t, body.ID)
+
+			if body.Input["prompt"] == nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"detail":"prompt is required"}`))
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"input":  body.Input,
+				"output": "hello " + body.Input["prompt"].(string),
+				"status": "succeeded",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := cog.NewClient(server.URL + "/")
+
+	version, err := c.GetVersion(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, version.OpenAPISchema)
+
+	prediction, err := c.Predict(ctx, replicate.PredictionInput{"prompt": "world"})
+	assert.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, "hello world", prediction.Output)
+	assert.Contains(t, prediction.ID, "local-")
+
+	_, err = c.Predict(ctx, replicate.PredictionInput{})
+	assert.ErrorContains(t, err, "prompt is required")
+}
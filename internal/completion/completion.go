@@ -0,0 +1,305 @@
+// Package completion completes arguments and flags in the shell
+// with models, predictions, trainings, deployments and hardware from the API,
+// and inputs from the schemas of cached model versions.
+package completion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/replicate/replicate-go"
+	"github.com/spf13/cobra"
+
+	"github.com/replicate/cli/internal/cache"
+	"github.com/replicate/cli/internal/client"
+	"github.com/replicate/cli/internal/identifier"
+	"github.com/replicate/cli/internal/util"
+)
+
+// Func completes the arguments or a flag of a command
+type Func func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// timeout limits how long completion waits for the API, since the shell waits for it
+const timeout = 5 * time.Second
+
+const noFiles = cobra.ShellCompDirectiveNoFileComp
+
+// withClient calls f with an API client, and returns nothing if there isn't one
+func withClient(cmd *cobra.Command, f func(ctx context.Context, r8 *replicate.Client) []string) ([]string, cobra.ShellCompDirective) {
+	r8, err := client.NewClient()
+	if err != nil {
+		return nil, noFiles
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	return f(ctx, r8), noFiles
+}
+
+// First completes the first argument with f, and no more arguments
+func First(f Func) Func {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, noFiles
+		}
+		return f(cmd, args, toComplete)
+	}
+}
+
+// Models completes model names, like owner/name, from cached models,
+// and the models of recent predictions, trainings and deployments.
+// After a colon, it completes the model's versions.
+func Models(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if name, _, ok := strings.Cut(toComplete, ":"); ok {
+		return versions(cmd, name, toComplete)
+	}
+
+	return withClient(cmd, func(ctx context.Context, r8 *replicate.Client) []string {
+		models := cache.Models()
+
+		if page, err := r8.ListPredictions(ctx); err == nil {
+			for _, p := range page.Results {
+				models = append(models, p.Model)
+			}
+		}
+		if page, err := r8.ListTrainings(ctx); err == nil {
+			for _, t := range page.Results {
+				models = append(models, t.Model)
+			}
+		}
+		if page, err := r8.ListDeployments(ctx); err == nil {
+			for _, d := range page.Results {
+				models = append(models, d.CurrentRelease.Model)
+			}
+		}
+
+		return filter(models, toComplete)
+	})
+}
+
+// versions completes owner/name:version with the versions of a model
+func versions(cmd *cobra.Command, name string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	id, err := identifier.ParseIdentifier(name)
+	if err != nil {
+		return nil, noFiles
+	}
+
+	return withClient(cmd, func(ctx context.Context, r8 *replicate.Client) []string {
+		page, err := r8.ListModelVersions(ctx, id.Owner, id.Name)
+		if err != nil {
+			return nil
+		}
+
+		completions := []string{}
+		for _, v := range page.Results {
+			completion := fmt.Sprintf("%s/%s:%s", id.Owner, id.Name, v.ID)
+			if strings.HasPrefix(completion, toComplete) {
+				completions = append(completions, completion+"\tcreated "+v.CreatedAt)
+			}
+		}
+		return completions
+	})
+}
+
+// Deployments completes deployment names, like owner/name
+func Deployments(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return withClient(cmd, func(ctx context.Context, r8 *replicate.Client) []string {
+		page, err := r8.ListDeployments(ctx)
+		if err != nil {
+			return nil
+		}
+
+		names := []string{}
+		for _, d := range page.Results {
+			names = append(names, d.Owner+"/"+d.Name)
+		}
+		return filter(names, toComplete)
+	})
+}
+
+// Predictions completes the IDs of recent predictions
+func Predictions(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return withClient(cmd, func(ctx context.Context, r8 *replicate.Client) []string {
+		page, err := r8.ListPredictions(ctx)
+		if err != nil {
+			return nil
+		}
+
+		completions := []string{}
+		for _, p := range page.Results {
+			if strings.HasPrefix(p.ID, toComplete) {
+				completions = append(completions, fmt.Sprintf("%s\t%s %s", p.ID, p.Model, p.Status))
+			}
+		}
+		return completions
+	})
+}
+
+// Trainings completes the IDs of recent trainings
+func Trainings(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return withClient(cmd, func(ctx context.Context, r8 *replicate.Client) []string {
+		page, err := r8.ListTrainings(ctx)
+		if err != nil {
+			return nil
+		}
+
+		completions := []string{}
+		for _, t := range page.Results {
+			if strings.HasPrefix(t.ID, toComplete) {
+				completions = append(completions, fmt.Sprintf("%s\t%s %s", t.ID, t.Model, t.Status))
+			}
+		}
+		return completions
+	})
+}
+
+// Hardware completes hardware SKUs
+func Hardware(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return withClient(cmd, func(ctx context.Context, r8 *replicate.Client) []string {
+		hardware, err := r8.ListHardware(ctx)
+		if err != nil {
+			return nil
+		}
+
+		completions := []string{}
+		for _, h := range *hardware {
+			if strings.HasPrefix(h.SKU, toComplete) {
+				completions = append(completions, h.SKU+"\t"+h.Name)
+			}
+		}
+		return completions
+	})
+}
+
+// ModelInputs completes a model for the first argument,
+// and its inputs, like name=value, for the rest
+func ModelInputs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return Models(cmd, args, toComplete)
+	}
+
+	id, err := identifier.ParseIdentifier(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	version := cache.LoadVersion(id.Owner, id.Name, id.Version)
+	if version == nil {
+		version = fetchVersion(cmd, id)
+	}
+	if version == nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	inputSchema, _, err := util.GetSchemas(*version)
+	if err != nil || inputSchema == nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	return Inputs(inputSchema, args[1:], toComplete)
+}
+
+// Inputs completes the names of inputs in a schema that haven't been given yet, followed by =,
+// and then the values of inputs with enums
+func Inputs(schema *openapi3.Schema, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if name, _, ok := strings.Cut(toComplete, "="); ok {
+		prop, ok := schema.Properties[name]
+		if !ok || prop.Value == nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		completions := []string{}
+		for _, value := range enum(prop.Value) {
+			completion := fmt.Sprintf("%s=%v", name, value)
+			if strings.HasPrefix(completion, toComplete) {
+				completions = append(completions, completion)
+			}
+		}
+		if len(completions) == 0 {
+			// Leave other values to the shell
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return completions, noFiles
+	}
+
+	given := map[string]bool{}
+	for _, arg := range args {
+		if name, _, ok := strings.Cut(arg, "="); ok {
+			given[name] = true
+		}
+	}
+
+	completions := []string{}
+	for _, name := range util.SortedKeys(schema.Properties) {
+		if given[name] || !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+
+		completion := name + "="
+		if prop := schema.Properties[name].Value; prop != nil && prop.Description != "" {
+			completion += "\t" + prop.Description
+		}
+		completions = append(completions, completion)
+	}
+
+	return completions, cobra.ShellCompDirectiveNoSpace | noFiles
+}
+
+// fetchVersion gets a model version from the API and caches it
+func fetchVersion(cmd *cobra.Command, id *identifier.Identifier) *replicate.ModelVersion {
+	r8, err := client.NewClient()
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	var version *replicate.ModelVersion
+	if id.Version != "" {
+		version, _ = r8.GetModelVersion(ctx, id.Owner, id.Name, id.Version)
+	} else if model, err := r8.GetModel(ctx, id.Owner, id.Name); err == nil {
+		version = model.LatestVersion
+	}
+
+	_ = cache.SaveVersion(id.Owner, id.Name, version, id.Version == "")
+
+	return version
+}
+
+// enum returns the allowed values of a schema,
+// which Cog puts in a referenced schema
+func enum(schema *openapi3.Schema) []interface{} {
+	if len(schema.Enum) > 0 {
+		return schema.Enum
+	}
+
+	for _, ref := range schema.AllOf {
+		if ref.Value != nil && len(ref.Value.Enum) > 0 {
+			return ref.Value.Enum
+		}
+	}
+
+	return nil
+}
+
+// filter returns the unique, non-empty values that start with a prefix, sorted
+func filter(values []string, prefix string) []string {
+	seen := map[string]bool{}
+	filtered := []string{}
+	for _, value := range values {
+		if value == "" || seen[value] || !strings.HasPrefix(value, prefix) {
+			continue
+		}
+		seen[value] = true
+		filtered = append(filtered, value)
+	}
+	sort.Strings(filtered)
+
+	return filtered
+}
@@ -0,0 +1,53 @@
+package completion_test
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/completion"
+)
+
+func TestInputs(t *testing.T) {
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(`{
+		"openapi": "3.0.2",
+		"info": {"title": "Cog", "version": "0.1.0"},
+		"paths": {},
+		"components": {
+			"schemas": {
+				"Input": {
+					"type": "object",
+					"properties": {
+						"prompt": {"type": "string", "description": "Input prompt", "x-order": 0},
+						"scheduler": {"allOf": [{"$ref": "#/components/schemas/scheduler"}], "x-order": 1},
+						"seed": {"type": "integer", "x-order": 2},
+						"size": {"type": "string", "enum": ["small", "large"], "x-order": 3}
+					}
+				},
+				"scheduler": {"type": "string", "enum": ["DDIM", "K_EULER", "PNDM"]}
+			}
+		}
+	}`))
+	assert.NoError(t, err)
+	schema := spec.Components.Schemas["Input"].Value
+
+	completions, directive := completion.Inputs(schema, []string{"seed=1"}, "")
+	assert.Equal(t, []string{"prompt=\tInput prompt", "scheduler=", "size="}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoSpace|cobra.ShellCompDirectiveNoFileComp, directive)
+
+	completions, _ = completion.Inputs(schema, nil, "s")
+	assert.Equal(t, []string{"scheduler=", "seed=", "size="}, completions)
+
+	completions, directive = completion.Inputs(schema, nil, "scheduler=K")
+	assert.Equal(t, []string{"scheduler=K_EULER"}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	completions, _ = completion.Inputs(schema, nil, "size=")
+	assert.Equal(t, []string{"size=small", "size=large"}, completions)
+
+	completions, directive = completion.Inputs(schema, nil, "prompt=")
+	assert.Empty(t, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveDefault, directive)
+}
@@ -0,0 +1,366 @@
+// Package history keeps a local record of the predictions and trainings the CLI creates,
+// so they can be found and referred to by number later.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/replicate/replicate-go"
+)
+
+const (
+	KindPrediction = "prediction"
+	KindTraining   = "training"
+)
+
+// Path is where history is kept.
+// Each line is an entry, and later lines for the same entry replace earlier ones.
+// Entries are numbered in the order they first appear,
+// so CLI processes running at once can append without coordinating.
+var Path string
+
+func init() {
+	// Look for history in the XDG_STATE_HOME directory
+	if stateDir, exists := os.LookupEnv("XDG_STATE_HOME"); exists {
+		Path = filepath.Join(stateDir, "replicate", "history.jsonl")
+	} else if homeDir, err := os.UserHomeDir(); err == nil {
+		Path = filepath.Join(homeDir, ".local", "state", "replicate", "history.jsonl")
+	}
+}
+
+// compactAfter is how many lines superseded by later ones history keeps
+// before it's rewritten with a line per entry
+const compactAfter = 500
+
+// maxDataURISize is the length of the longest data URI input that's kept in history.
+// Longer ones are replaced with a placeholder, so history stays small.
+const maxDataURISize = 1024
+
+// mu serializes writes from predictions created concurrently in a process, like by compare
+var mu sync.Mutex
+
+// Entry is a prediction or training created by the CLI
+type Entry struct {
+	Number      int                    `json:"number,omitempty"`
+	Kind        string                 `json:"kind"`
+	ID          string                 `json:"id"`
+	Model       string                 `json:"model,omitempty"`
+	Version     string                 `json:"version,omitempty"`
+	Input       map[string]interface{} `json:"input,omitempty"`
+	Output      interface{}            `json:"output,omitempty"`
+	Error       interface{}            `json:"error,omitempty"`
+	Status      replicate.Status       `json:"status"`
+	CreatedAt   string                 `json:"created_at,omitempty"`
+	StartedAt   string                 `json:"started_at,omitempty"`
+	CompletedAt string                 `json:"completed_at,omitempty"`
+	PredictTime *float64               `json:"predict_time,omitempty"`
+	Command     string                 `json:"command"`
+}
+
+// RecordPrediction adds a prediction to history, or updates it if it's already there
+func RecordPrediction(prediction *replicate.Prediction) error {
+	return record(KindPrediction, prediction)
+}
+
+// RecordTraining adds a training to history, or updates it if it's already there
+func RecordTraining(training *replicate.Training) error {
+	return record(KindTraining, (*replicate.Prediction)(training))
+}
+
+func record(kind string, prediction *replicate.Prediction) error {
+	if Path == "" || prediction == nil || prediction.ID == "" {
+		return nil
+	}
+
+	entry := Entry{
+		Kind:      kind,
+		ID:        prediction.ID,
+		Model:     prediction.Model,
+		Version:   prediction.Version,
+		Input:     shortenDataURIs(prediction.Input),
+		Output:    prediction.Output,
+		Error:     prediction.Error,
+		Status:    prediction.Status,
+		CreatedAt: prediction.CreatedAt,
+		Command:   commandLine(os.Args),
+	}
+	if prediction.StartedAt != nil {
+		entry.StartedAt = *prediction.StartedAt
+	}
+	if prediction.CompletedAt != nil {
+		entry.CompletedAt = *prediction.CompletedAt
+	}
+	if prediction.Metrics != nil {
+		entry.PredictTime = prediction.Metrics.PredictTime
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(Path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	// Start a new line after one that was only partly written
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
+
+// Load returns the entries in history, oldest first.
+// History with many superseded lines is compacted.
+func Load() ([]Entry, error) {
+	if Path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []Entry
+	indexes := map[string]int{}
+	lines := 0
+	reader := bufio.NewReader(f)
+	for {
+		// Lines can be any length, since inputs can be large
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		if len(line) == 0 {
+			break
+		}
+		lines++
+
+		var entry Entry
+		// Skip lines that were only partly written
+		if err := json.Unmarshal(line, &entry); err != nil || entry.Kind == "" || entry.ID == "" {
+			continue
+		}
+
+		// Updates keep the number and command line of the original entry
+		key := entry.Kind + "/" + entry.ID
+		if i, ok := indexes[key]; ok {
+			entry.Number = entries[i].Number
+			entry.Command = entries[i].Command
+			entries[i] = entry
+			continue
+		}
+
+		entry.Number = len(entries) + 1
+		indexes[key] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	if lines-len(entries) > compactAfter {
+		_ = compact(entries, info.Size())
+	}
+
+	return entries, nil
+}
+
+// compact rewrites history with a line per entry, in order, so their numbers stay the same.
+// It's skipped if history has been written since it was read with the given size.
+func compact(entries []Entry, size int64) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(Path), "history-*.jsonl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		entry.Number = 0
+		data, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		_, _ = w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Another process may have appended an entry since history was read
+	if info, err := os.Stat(Path); err != nil || info.Size() != size {
+		return fmt.Errorf("history changed while compacting")
+	}
+
+	return os.Rename(tmp.Name(), Path)
+}
+
+// shortenDataURIs replaces long data URI inputs with a placeholder like "data:image/png;base64,... (123456 bytes)"
+func shortenDataURIs(input map[string]interface{}) map[string]interface{} {
+	if input == nil {
+		return nil
+	}
+
+	shortened := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		shortened[k] = shortenDataURI(v)
+	}
+	return shortened
+}
+
+func shortenDataURI(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(v, "data:") || len(v) <= maxDataURISize {
+			return v
+		}
+		prefix, _, _ := strings.Cut(v, ",")
+		return fmt.Sprintf("%s,... (%d bytes)", prefix, len(v))
+	case []interface{}:
+		shortened := make([]interface{}, len(v))
+		for i, item := range v {
+			shortened[i] = shortenDataURI(item)
+		}
+		return shortened
+	case map[string]interface{}:
+		return shortenDataURIs(v)
+	}
+
+	return value
+}
+
+// Get returns the entry with a number
+func Get(number int) (*Entry, error) {
+	entries, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Number == number {
+			return &entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no history entry %d", number)
+}
+
+// Resolve returns the ID of a prediction or training of the given kind,
+// if value is the number of a history entry rather than an ID
+func Resolve(value string, kind string) (string, bool, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return value, false, nil
+	}
+
+	entry, err := Get(number)
+	if err != nil {
+		return "", true, err
+	}
+	if entry.Kind != kind {
+		return "", true, fmt.Errorf("history entry %d is a %s, not a %s", number, entry.Kind, kind)
+	}
+
+	return entry.ID, true, nil
+}
+
+// Filter selects entries
+type Filter struct {
+	// Query matches entries whose ID, model, version, inputs or command line contain it
+	Query  string
+	Kind   string
+	Model  string
+	Status string
+}
+
+// Match reports whether an entry matches a filter
+func (f Filter) Match(entry Entry) bool {
+	if f.Kind != "" && entry.Kind != f.Kind {
+		return false
+	}
+	if f.Model != "" && entry.Model != f.Model {
+		return false
+	}
+	if f.Status != "" && string(entry.Status) != f.Status {
+		return false
+	}
+	if f.Query == "" {
+		return true
+	}
+
+	input, _ := json.Marshal(entry.Input)
+	query := strings.ToLower(f.Query)
+	for _, s := range []string{entry.ID, entry.Model, entry.Version, string(input), entry.Command} {
+		if strings.Contains(strings.ToLower(s), query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commandLine returns a command line that runs args again in a shell
+func commandLine(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	quoted := []string{filepath.Base(args[0])}
+	for _, arg := range args[1:] {
+		quoted = append(quoted, quote(arg))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// quote quotes an argument for a POSIX shell if it needs it
+func quote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_=/.:,@%+", r))
+	}) == -1 {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
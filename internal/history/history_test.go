@@ -0,0 +1,178 @@
+package history_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/replicate/replicate-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/history"
+)
+
+func TestRecord(t *testing.T) {
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+
+	entries, err := history.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	prediction := &replicate.Prediction{ID: "p1", Model: "replicate/hello-world", Status: replicate.Starting, Input: replicate.PredictionInput{"text": "world"}}
+	assert.NoError(t, history.RecordPrediction(prediction))
+	assert.NoError(t, history.RecordTraining(&replicate.Training{ID: "t1", Model: "replicate/hello-world", Status: replicate.Starting}))
+
+	// Updating a prediction keeps its number
+	prediction.Status = replicate.Succeeded
+	prediction.Output = "hello world"
+	assert.NoError(t, history.RecordPrediction(prediction))
+
+	entries, err = history.Load()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 1, entries[0].Number)
+	assert.Equal(t, history.KindPrediction, entries[0].Kind)
+	assert.Equal(t, replicate.Succeeded, entries[0].Status)
+	assert.Equal(t, "hello world", entries[0].Output)
+	assert.Equal(t, 2, entries[1].Number)
+	assert.Equal(t, history.KindTraining, entries[1].Kind)
+
+	entry, err := history.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "t1", entry.ID)
+	_, err = history.Get(3)
+	assert.Error(t, err)
+}
+
+func TestLoadSkipsPartialLines(t *testing.T) {
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+	assert.NoError(t, os.WriteFile(history.Path, []byte(`{"number":1,"kind":"prediction","id":"p1"}`+"\n"+`{"number":2,"ki`), 0o600))
+
+	entries, err := history.Load()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// New entries are numbered after existing ones
+	assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "p2"}))
+	entry, err := history.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", entry.ID)
+}
+
+func TestLoadNumbersEntriesInOrder(t *testing.T) {
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+
+	// Processes that recorded at the same time may have written the same number
+	lines := `{"number":1,"kind":"prediction","id":"p1","status":"starting","command":"replicate run a"}
+{"number":1,"kind":"prediction","id":"p2","status":"starting","command":"replicate run b"}
+{"kind":"prediction","id":"p1","status":"succeeded","command":"replicate run c"}
+`
+	assert.NoError(t, os.WriteFile(history.Path, []byte(lines), 0o600))
+
+	entries, err := history.Load()
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, 1, entries[0].Number)
+		assert.Equal(t, "p1", entries[0].ID)
+		assert.Equal(t, replicate.Succeeded, entries[0].Status)
+		assert.Equal(t, "replicate run a", entries[0].Command)
+		assert.Equal(t, 2, entries[1].Number)
+		assert.Equal(t, "p2", entries[1].ID)
+	}
+}
+
+func TestLoadLongLines(t *testing.T) {
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+
+	// Lines longer than a scanner's limit are still read
+	long := `{"kind":"prediction","id":"p1","input":{"text":"` + strings.Repeat("a", 17*1024*1024) + `"}}`
+	assert.NoError(t, os.WriteFile(history.Path, []byte(long+"\n"), 0o600))
+	assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "p2"}))
+
+	entries, err := history.Load()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestRecordShortensDataURIs(t *testing.T) {
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+
+	image := "data:image/png;base64," + strings.Repeat("A", 4096)
+	assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "p1", Input: replicate.PredictionInput{
+		"image":  image,
+		"images": []interface{}{image},
+		"small":  "data:text/plain;base64,aGk=",
+	}}))
+
+	entry, err := history.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "data:image/png;base64,... (4118 bytes)", entry.Input["image"])
+	assert.Equal(t, []interface{}{"data:image/png;base64,... (4118 bytes)"}, entry.Input["images"])
+	assert.Equal(t, "data:text/plain;base64,aGk=", entry.Input["small"])
+}
+
+func TestLoadCompacts(t *testing.T) {
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+
+	assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "p1", Status: replicate.Starting}))
+	for i := 0; i < 600; i++ {
+		assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "p2", Status: replicate.Processing}))
+	}
+	assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "p2", Status: replicate.Succeeded}))
+
+	entries, err := history.Load()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Superseded lines are dropped, and entries keep their numbers
+	data, err := os.ReadFile(history.Path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+
+	entry, err := history.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", entry.ID)
+	assert.Equal(t, replicate.Succeeded, entry.Status)
+}
+
+func TestResolve(t *testing.T) {
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+	assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "p1"}))
+	assert.NoError(t, history.RecordTraining(&replicate.Training{ID: "t1"}))
+
+	id, ok, err := history.Resolve("1", history.KindPrediction)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "p1", id)
+
+	id, ok, err = history.Resolve("jpgp263bdekvxileu2ppsy46v4", history.KindPrediction)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "jpgp263bdekvxileu2ppsy46v4", id)
+
+	_, ok, err = history.Resolve("2", history.KindPrediction)
+	assert.True(t, ok)
+	assert.ErrorContains(t, err, "is a training")
+
+	_, _, err = history.Resolve("3", history.KindPrediction)
+	assert.Error(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	entry := history.Entry{
+		Kind:    history.KindPrediction,
+		ID:      "p1",
+		Model:   "stability-ai/sdxl",
+		Status:  replicate.Failed,
+		Input:   map[string]interface{}{"prompt": "A Corgi"},
+		Command: "replicate run stability-ai/sdxl 'prompt=A Corgi'",
+	}
+
+	assert.True(t, history.Filter{}.Match(entry))
+	assert.True(t, history.Filter{Query: "corgi"}.Match(entry))
+	assert.False(t, history.Filter{Query: "poodle"}.Match(entry))
+	assert.True(t, history.Filter{Model: "stability-ai/sdxl", Status: "failed"}.Match(entry))
+	assert.False(t, history.Filter{Model: "stability-ai/sdxl", Status: "succeeded"}.Match(entry))
+	assert.False(t, history.Filter{Kind: history.KindTraining}.Match(entry))
+}
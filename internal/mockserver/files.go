@@ -0,0 +1,167 @@
+package mockserver
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// file is an uploaded file and its contents
+type file struct {
+	file    replicate.File
+	content []byte
+}
+
+func (s *Server) serveFiles(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		ids := s.order["file"]
+		results := []interface{}{}
+		for i := len(ids) - 1; i >= 0; i-- {
+			if f, ok := s.files[ids[i]]; ok {
+				results = append(results, f.file)
+			}
+		}
+		s.writePage(w, r, results)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		s.createFile(w, r)
+	case len(parts) >= 1:
+		f, ok := s.files[parts[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not found.")
+			return
+		}
+
+		switch {
+		case len(parts) == 1 && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, f.file)
+		case len(parts) == 1 && r.Method == http.MethodDelete:
+			delete(s.files, parts[0])
+			w.WriteHeader(http.StatusNoContent)
+		case len(parts) == 2 && parts[1] == "download" && r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", f.file.ContentType)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(f.content)
+		default:
+			writeError(w, http.StatusNotFound, "Not found.")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+func (s *Server) createFile(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid multipart form: %s", err))
+		return
+	}
+
+	content, header, err := r.FormFile("content")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "The content field is required")
+		return
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	metadata := map[string]string{}
+	if value := r.FormValue("metadata"); value != "" {
+		if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid metadata: %s", err))
+			return
+		}
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	md5sum := md5.Sum(data)
+	sha256sum := sha256.Sum256(data)
+
+	id := s.newID("file")
+	createdAt := s.now()
+
+	f := &file{
+		content: data,
+		file: replicate.File{
+			ID:          id,
+			Name:        header.Filename,
+			ContentType: contentType,
+			Size:        len(data),
+			Etag:        hex.EncodeToString(md5sum[:]),
+			Checksums:   map[string]string{"sha256": hex.EncodeToString(sha256sum[:])},
+			Metadata:    metadata,
+			CreatedAt:   createdAt.Format(time.RFC3339),
+			ExpiresAt:   createdAt.Add(24 * time.Hour).Format(time.RFC3339),
+			URLs:        map[string]string{"get": fmt.Sprintf("%s/files/%s/download", baseURL(r), id)},
+		},
+	}
+	s.files[id] = f
+	s.track("file", id)
+
+	writeJSON(w, http.StatusCreated, f.file)
+}
+
+// serveOutput serves the files in prediction outputs,
+// which are small PNG images or placeholder training weights
+func (s *Server) serveOutput(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) != 2 || r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	_, isPrediction := s.predictions[parts[0]]
+	_, isTraining := s.trainings[parts[0]]
+	if !isPrediction && !isTraining {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch path.Ext(parts[1]) {
+	case ".png":
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(outputImage(parts[0] + "/" + parts[1]))
+	case ".tar":
+		w.Header().Set("Content-Type", "application/x-tar")
+		_, _ = w.Write([]byte(strings.Repeat("mock weights\n", 8)))
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// outputImage returns an 8x8 PNG with a color derived from name
+func outputImage(name string) []byte {
+	sum := sha256.Sum256([]byte(name))
+	fill := color.RGBA{R: sum[0], G: sum[1], B: sum[2], A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
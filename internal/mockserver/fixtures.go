@@ -0,0 +1,216 @@
+package mockserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Username is the mock account's username
+const Username = "test-user"
+
+// WebhookSecret is the mock account's default webhook signing secret
+const WebhookSecret = "whsec_bW9jay13ZWJob29rLXNlY3JldA=="
+
+// FailInput is an input that makes any prediction or training fail when it's true
+const FailInput = "fail"
+
+// model is a model and its versions, newest first,
+// with a function that makes the output of its predictions
+type model struct {
+	model    replicate.Model
+	versions []*replicate.ModelVersion
+
+	// predict returns the output for a run
+	predict func(r *run) interface{}
+}
+
+// loadFixtures adds the account, hardware, models and deployments the server starts with:
+//
+//   - replicate/hello-world says hello to its text input
+//   - stability-ai/sdxl makes num_outputs PNG images
+//   - meta/llama-2-7b-chat streams its prompt back a word at a time
+//   - test-user/hello-world-fine-tuned has no versions, for trainings to push to
+//   - the test-user/hello-world deployment runs replicate/hello-world
+func (s *Server) loadFixtures() {
+	s.account = replicate.Account{
+		Type:      "user",
+		Username:  Username,
+		Name:      "Test User",
+		GithubURL: "https://github.com/" + Username,
+	}
+
+	s.hardware = []replicate.Hardware{
+		{SKU: "cpu", Name: "CPU"},
+		{SKU: "gpu-t4", Name: "Nvidia T4 GPU"},
+		{SKU: "gpu-a40-small", Name: "Nvidia A40 GPU"},
+		{SKU: "gpu-a40-large", Name: "Nvidia A40 (Large) GPU"},
+	}
+
+	s.addModel("replicate", "hello-world", "A tiny model that says hello",
+		withTrainingInput(
+			schema(
+				properties{
+					"text": {"type": "string", "title": "Text", "description": "Text to prefix with 'hello '", "x-order": 0},
+				},
+				[]string{"text"},
+				map[string]interface{}{"type": "string", "title": "Output"},
+			),
+			properties{
+				"text":   {"type": "string", "title": "Text", "description": "Text to train on", "x-order": 0},
+				"epochs": {"type": "integer", "title": "Epochs", "description": "Number of epochs", "default": 1, "x-order": 1},
+			},
+		),
+		func(r *run) interface{} {
+			return fmt.Sprintf("hello %v", r.prediction.Input["text"])
+		},
+	)
+
+	s.addModel("stability-ai", "sdxl", "A text-to-image generative AI model that creates beautiful images",
+		schema(
+			properties{
+				"prompt":      {"type": "string", "title": "Prompt", "description": "Input prompt", "x-order": 0},
+				"num_outputs": {"type": "integer", "title": "Num Outputs", "description": "Number of images to output", "default": 1, "minimum": 1, "maximum": 4, "x-order": 1},
+				"seed":        {"type": "integer", "title": "Seed", "description": "Random seed", "x-order": 2},
+			},
+			[]string{"prompt"},
+			map[string]interface{}{"type": "array", "title": "Output", "items": map[string]interface{}{"type": "string", "format": "uri"}},
+		),
+		func(r *run) interface{} {
+			count := 1
+			if n, ok := r.prediction.Input["num_outputs"].(float64); ok && n > 0 {
+				count = int(n)
+			}
+
+			output := make([]interface{}, count)
+			for i := range output {
+				output[i] = fmt.Sprintf("%s/outputs/%s/out-%d.png", r.baseURL, r.prediction.ID, i)
+			}
+			return output
+		},
+	)
+
+	s.addModel("meta", "llama-2-7b-chat", "A 7 billion parameter language model fine-tuned for chat completions",
+		schema(
+			properties{
+				"prompt":         {"type": "string", "title": "Prompt", "description": "Prompt to send to the model", "x-order": 0},
+				"max_new_tokens": {"type": "integer", "title": "Max New Tokens", "description": "Maximum number of tokens to generate", "default": 128, "minimum": 1, "x-order": 1},
+			},
+			[]string{"prompt"},
+			map[string]interface{}{
+				"type":                "array",
+				"title":               "Output",
+				"items":               map[string]interface{}{"type": "string"},
+				"x-cog-array-type":    "iterator",
+				"x-cog-array-display": "concatenate",
+			},
+		),
+		func(r *run) interface{} {
+			words := strings.Fields(fmt.Sprintf("You said: %v", r.prediction.Input["prompt"]))
+			output := make([]interface{}, len(words))
+			for i, word := range words {
+				if i > 0 {
+					word = " " + word
+				}
+				output[i] = word
+			}
+			return output
+		},
+	)
+
+	s.models[Username+"/hello-world-fine-tuned"] = &model{
+		model: replicate.Model{
+			URL:        "https://replicate.com/" + Username + "/hello-world-fine-tuned",
+			Owner:      Username,
+			Name:       "hello-world-fine-tuned",
+			Visibility: "private",
+		},
+		predict: s.models["replicate/hello-world"].predict,
+	}
+
+	helloWorld := s.models["replicate/hello-world"]
+	s.deployments[Username+"/hello-world"] = &replicate.Deployment{
+		Owner: Username,
+		Name:  "hello-world",
+		CurrentRelease: replicate.DeploymentRelease{
+			Number:    1,
+			Model:     "replicate/hello-world",
+			Version:   helloWorld.versions[0].ID,
+			CreatedAt: Epoch.Format("2006-01-02T15:04:05Z"),
+			CreatedBy: s.account,
+			Configuration: replicate.DeploymentConfiguration{
+				Hardware:     "cpu",
+				MinInstances: 0,
+				MaxInstances: 1,
+			},
+		},
+	}
+}
+
+// addModel adds a public model with one version
+func (s *Server) addModel(owner string, name string, description string, openAPISchema map[string]interface{}, predict func(r *run) interface{}) {
+	version := &replicate.ModelVersion{
+		ID:            s.newID("version"),
+		CreatedAt:     Epoch.Format("2006-01-02T15:04:05Z"),
+		CogVersion:    "0.9.0",
+		OpenAPISchema: openAPISchema,
+	}
+
+	s.models[owner+"/"+name] = &model{
+		model: replicate.Model{
+			URL:           fmt.Sprintf("https://replicate.com/%s/%s", owner, name),
+			Owner:         owner,
+			Name:          name,
+			Description:   description,
+			Visibility:    "public",
+			LatestVersion: version,
+		},
+		versions: []*replicate.ModelVersion{version},
+		predict:  predict,
+	}
+}
+
+type properties map[string]map[string]interface{}
+
+// schema returns an OpenAPI schema like the ones Cog makes for a model
+func schema(inputs properties, required []string, output map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	for name, prop := range inputs {
+		props[name] = prop
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.2",
+		"info":    map[string]interface{}{"title": "Cog", "version": "0.1.0"},
+		"paths":   map[string]interface{}{},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Input": map[string]interface{}{
+					"type":       "object",
+					"title":      "Input",
+					"properties": props,
+					"required":   required,
+				},
+				"Output": output,
+			},
+		},
+	}
+}
+
+// withTrainingInput adds a schema for training inputs to a model's schema, for models that can be trained
+func withTrainingInput(openAPISchema map[string]interface{}, inputs properties) map[string]interface{} {
+	props := map[string]interface{}{}
+	for name, prop := range inputs {
+		props[name] = prop
+	}
+
+	schemas := openAPISchema["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	schemas["TrainingInput"] = map[string]interface{}{
+		"type":       "object",
+		"title":      "TrainingInput",
+		"properties": props,
+	}
+
+	return openAPISchema
+}
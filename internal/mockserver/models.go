@@ -0,0 +1,251 @@
+package mockserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/replicate/replicate-go"
+)
+
+type createModelRequest struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	replicate.CreateModelOptions
+}
+
+func (s *Server) serveModels(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			results := []interface{}{}
+			for _, key := range sortedKeys(s.models) {
+				results = append(results, s.models[key].model)
+			}
+			s.writePage(w, r, results)
+		case http.MethodPost:
+			var body createModelRequest
+			if err := decodeBody(r, &body); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			key := body.Owner + "/" + body.Name
+			if body.Owner == "" || body.Name == "" {
+				writeError(w, http.StatusBadRequest, "A model needs an owner and a name")
+				return
+			}
+			if _, ok := s.models[key]; ok {
+				writeError(w, http.StatusConflict, fmt.Sprintf("A model named %s already exists", key))
+				return
+			}
+
+			m := &model{
+				model: replicate.Model{
+					URL:        "https://replicate.com/" + key,
+					Owner:      body.Owner,
+					Name:       body.Name,
+					Visibility: body.Visibility,
+				},
+				predict: s.models["replicate/hello-world"].predict,
+			}
+			if body.Description != nil {
+				m.model.Description = *body.Description
+			}
+			s.models[key] = m
+
+			writeJSON(w, http.StatusCreated, m.model)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		}
+		return
+	}
+
+	if len(parts) < 2 {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	key := parts[0] + "/" + parts[1]
+	m, ok := s.models[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, m.model)
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		if len(m.versions) > 0 {
+			writeError(w, http.StatusConflict, "Only models without versions can be deleted")
+			return
+		}
+		delete(s.models, key)
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[2] == "versions" && r.Method == http.MethodGet:
+		results := make([]interface{}, len(m.versions))
+		for i, version := range m.versions {
+			results[i] = version
+		}
+		s.writePage(w, r, results)
+	case len(parts) == 3 && parts[2] == "predictions" && r.Method == http.MethodPost:
+		if m.model.LatestVersion == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("The model %s has no versions", key))
+			return
+		}
+
+		var body createRunRequest
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, s.createRun(r, "prediction", m, m.model.LatestVersion, "", body))
+	case len(parts) >= 4 && parts[2] == "versions":
+		var version *replicate.ModelVersion
+		index := -1
+		for i, v := range m.versions {
+			if v.ID == parts[3] {
+				version, index = v, i
+			}
+		}
+		if version == nil {
+			writeError(w, http.StatusNotFound, "Not found.")
+			return
+		}
+
+		switch {
+		case len(parts) == 4 && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, version)
+		case len(parts) == 4 && r.Method == http.MethodDelete:
+			m.versions = append(m.versions[:index], m.versions[index+1:]...)
+			m.model.LatestVersion = nil
+			if len(m.versions) > 0 {
+				m.model.LatestVersion = m.versions[0]
+			}
+			w.WriteHeader(http.StatusAccepted)
+		case len(parts) == 5 && parts[4] == "trainings" && r.Method == http.MethodPost:
+			s.createTraining(w, r, m, version)
+		default:
+			writeError(w, http.StatusNotFound, "Not found.")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+func (s *Server) serveDeployments(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			results := []interface{}{}
+			for _, key := range sortedKeys(s.deployments) {
+				results = append(results, s.deployments[key])
+			}
+			s.writePage(w, r, results)
+		case http.MethodPost:
+			var body replicate.CreateDeploymentOptions
+			if err := decodeBody(r, &body); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			key := Username + "/" + body.Name
+			if _, ok := s.deployments[key]; ok {
+				writeError(w, http.StatusConflict, fmt.Sprintf("A deployment named %s already exists", key))
+				return
+			}
+			if _, version := s.findVersion(body.Version); version == nil {
+				writeError(w, http.StatusNotFound, fmt.Sprintf("Version %s not found", body.Version))
+				return
+			}
+
+			d := &replicate.Deployment{
+				Owner: Username,
+				Name:  body.Name,
+				CurrentRelease: replicate.DeploymentRelease{
+					Number:    1,
+					Model:     body.Model,
+					Version:   body.Version,
+					CreatedAt: s.timestamp(),
+					CreatedBy: s.account,
+					Configuration: replicate.DeploymentConfiguration{
+						Hardware:     body.Hardware,
+						MinInstances: body.MinInstances,
+						MaxInstances: body.MaxInstances,
+					},
+				},
+			}
+			s.deployments[key] = d
+
+			writeJSON(w, http.StatusCreated, d)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		}
+		return
+	}
+
+	if len(parts) < 2 {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	key := parts[0] + "/" + parts[1]
+	d, ok := s.deployments[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, d)
+	case len(parts) == 2 && r.Method == http.MethodPatch:
+		var body replicate.UpdateDeploymentOptions
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		release := d.CurrentRelease
+		release.Number++
+		release.CreatedAt = s.timestamp()
+		if body.Model != nil {
+			release.Model = *body.Model
+		}
+		if body.Version != nil {
+			release.Version = *body.Version
+		}
+		if body.Hardware != nil {
+			release.Configuration.Hardware = *body.Hardware
+		}
+		if body.MinInstances != nil {
+			release.Configuration.MinInstances = *body.MinInstances
+		}
+		if body.MaxInstances != nil {
+			release.Configuration.MaxInstances = *body.MaxInstances
+		}
+		d.CurrentRelease = release
+
+		writeJSON(w, http.StatusOK, d)
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		delete(s.deployments, key)
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[2] == "predictions" && r.Method == http.MethodPost:
+		m, version := s.findVersion(d.CurrentRelease.Version)
+		if m == nil {
+			writeError(w, http.StatusNotFound, "The deployment's version no longer exists")
+			return
+		}
+
+		var body createRunRequest
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, s.createRun(r, "prediction", m, version, key, body))
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
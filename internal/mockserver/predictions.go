@@ -0,0 +1,397 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// run is a prediction or a training
+type run struct {
+	prediction replicate.Prediction
+	model      *model
+	baseURL    string
+
+	// destination is the model a training pushes a new version to
+	destination string
+
+	// deployment is the deployment that created a prediction, if any
+	deployment string
+
+	polls int
+}
+
+// runResponse is a prediction or training as the API returns it
+type runResponse struct {
+	replicate.Prediction
+	Deployment string `json:"deployment,omitempty"`
+}
+
+func (p *run) response() runResponse {
+	return runResponse{Prediction: p.prediction, Deployment: p.deployment}
+}
+
+type createRunRequest struct {
+	Version             string                       `json:"version"`
+	Destination         string                       `json:"destination"`
+	Input               map[string]interface{}       `json:"input"`
+	Webhook             *string                      `json:"webhook"`
+	WebhookEventsFilter []replicate.WebhookEventType `json:"webhook_events_filter"`
+	Stream              bool                         `json:"stream"`
+}
+
+func (s *Server) servePredictions(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.writeRuns(w, r, "prediction", s.predictions)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var body createRunRequest
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		m, version := s.findVersion(body.Version)
+		if m == nil {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Invalid version or not permitted: %s", body.Version))
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, s.createRun(r, "prediction", m, version, "", body))
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		p, ok := s.predictions[parts[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not found.")
+			return
+		}
+
+		s.advance(p)
+		writeJSON(w, http.StatusOK, p.response())
+	case len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost:
+		p, ok := s.predictions[parts[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not found.")
+			return
+		}
+
+		s.cancel(p)
+		writeJSON(w, http.StatusOK, p.response())
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+func (s *Server) serveTrainings(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.writeRuns(w, r, "training", s.trainings)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		t, ok := s.trainings[parts[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not found.")
+			return
+		}
+
+		s.advance(t)
+		writeJSON(w, http.StatusOK, t.response())
+	case len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost:
+		t, ok := s.trainings[parts[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not found.")
+			return
+		}
+
+		s.cancel(t)
+		writeJSON(w, http.StatusOK, t.response())
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// createTraining handles POST /models/{owner}/{name}/versions/{id}/trainings
+func (s *Server) createTraining(w http.ResponseWriter, r *http.Request, m *model, version *replicate.ModelVersion) {
+	var body createRunRequest
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := s.models[body.Destination]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("The destination model %s does not exist", body.Destination))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.createRun(r, "training", m, version, "", body))
+}
+
+// writeRuns writes a page of predictions or trainings, newest first
+func (s *Server) writeRuns(w http.ResponseWriter, r *http.Request, kind string, runs map[string]*run) {
+	ids := s.order[kind]
+	results := make([]interface{}, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		results = append(results, runs[ids[i]].response())
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) findVersion(id string) (*model, *replicate.ModelVersion) {
+	for _, key := range sortedKeys(s.models) {
+		m := s.models[key]
+		for _, version := range m.versions {
+			if version.ID == id {
+				return m, version
+			}
+		}
+	}
+	return nil, nil
+}
+
+// createRun creates a prediction or training, which finishes right away if Steps is zero.
+// Predictions created with a deployment are given its name.
+func (s *Server) createRun(r *http.Request, kind string, m *model, version *replicate.ModelVersion, deployment string, body createRunRequest) runResponse {
+	id := s.newID(kind)
+	base := baseURL(r)
+
+	path := "predictions"
+	if kind == "training" {
+		path = "trainings"
+	}
+
+	input := body.Input
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+
+	p := &run{
+		model:       m,
+		baseURL:     base,
+		destination: body.Destination,
+		deployment:  deployment,
+		prediction: replicate.Prediction{
+			ID:                  id,
+			Status:              replicate.Starting,
+			Model:               m.model.Owner + "/" + m.model.Name,
+			Version:             version.ID,
+			Input:               input,
+			Source:              replicate.SourceAPI,
+			Webhook:             body.Webhook,
+			WebhookEventsFilter: body.WebhookEventsFilter,
+			CreatedAt:           s.timestamp(),
+			URLs: map[string]string{
+				"get":    fmt.Sprintf("%s/%s/%s", base, path, id),
+				"cancel": fmt.Sprintf("%s/%s/%s/cancel", base, path, id),
+			},
+		},
+	}
+
+	if body.Stream && kind == "prediction" {
+		p.prediction.URLs["stream"] = fmt.Sprintf("%s/stream/%s", base, id)
+	}
+
+	if kind == "training" {
+		s.trainings[id] = p
+	} else {
+		s.predictions[id] = p
+	}
+	s.track(kind, id)
+
+	if s.Steps <= 0 {
+		s.complete(p)
+	}
+
+	return p.response()
+}
+
+// advance moves a run one step closer to finishing, as if it were polled
+func (s *Server) advance(p *run) {
+	if p.prediction.Status.Terminated() {
+		return
+	}
+
+	p.polls++
+	if p.polls >= s.Steps {
+		s.complete(p)
+		return
+	}
+
+	s.start(p)
+	logs := *p.prediction.Logs + progressLine(p.polls, s.Steps)
+	p.prediction.Logs = &logs
+}
+
+func (s *Server) start(p *run) {
+	if p.prediction.Status != replicate.Starting {
+		return
+	}
+
+	startedAt := s.timestamp()
+	logs := "Running predict()...\n"
+	p.prediction.Status = replicate.Processing
+	p.prediction.StartedAt = &startedAt
+	p.prediction.Logs = &logs
+}
+
+// complete finishes a run, which fails if its FailInput is true
+func (s *Server) complete(p *run) {
+	s.start(p)
+
+	completedAt := s.timestamp()
+	p.prediction.CompletedAt = &completedAt
+
+	logs := *p.prediction.Logs + progressLine(s.Steps, s.Steps)
+
+	if failed, _ := p.prediction.Input[FailInput].(bool); failed {
+		logs += "Error: mock failure\n"
+		p.prediction.Status = replicate.Failed
+		p.prediction.Error = "mock failure"
+	} else {
+		p.prediction.Status = replicate.Succeeded
+		if p.destination != "" {
+			p.prediction.Output = s.pushVersion(p)
+		} else {
+			p.prediction.Output = p.model.predict(p)
+		}
+	}
+	p.prediction.Logs = &logs
+
+	started, _ := time.Parse(time.RFC3339, *p.prediction.StartedAt)
+	completed, _ := time.Parse(time.RFC3339, completedAt)
+	predictTime := completed.Sub(started).Seconds()
+	p.prediction.Metrics = &replicate.PredictionMetrics{PredictTime: &predictTime}
+}
+
+// pushVersion adds a version made by a training to its destination model,
+// and returns the training's output
+func (s *Server) pushVersion(p *run) interface{} {
+	destination := s.models[p.destination]
+
+	version := &replicate.ModelVersion{
+		ID:            s.newID("version"),
+		CreatedAt:     *p.prediction.CompletedAt,
+		CogVersion:    "0.9.0",
+		OpenAPISchema: p.model.versions[0].OpenAPISchema,
+	}
+	destination.versions = append([]*replicate.ModelVersion{version}, destination.versions...)
+	destination.model.LatestVersion = version
+
+	return map[string]interface{}{
+		"version": p.destination + ":" + version.ID,
+		"weights": fmt.Sprintf("%s/outputs/%s/weights.tar", p.baseURL, p.prediction.ID),
+	}
+}
+
+func (s *Server) cancel(p *run) {
+	if p.prediction.Status.Terminated() {
+		return
+	}
+
+	completedAt := s.timestamp()
+	p.prediction.Status = replicate.Canceled
+	p.prediction.CompletedAt = &completedAt
+}
+
+// progressLine returns a line of logs like the progress bars of tqdm
+func progressLine(current int, total int) string {
+	if total <= 0 {
+		total, current = 1, 1
+	}
+	percent := current * 100 / total
+	filled := current * 10 / total
+	return fmt.Sprintf("%3d%%|%s%s| %d/%d\n", percent, strings.Repeat("█", filled), strings.Repeat(" ", 10-filled), current, total)
+}
+
+// streamLinger is how long a stream stays open after its done event,
+// unless the client disconnects first.
+// replicate-go reconnects if a stream closes before it handles the done event.
+const streamLinger = 5 * time.Second
+
+// serveStream sends a prediction's logs and output as server-sent events,
+// finishing the prediction first
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) != 1 || r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	events, ok := s.streamEvents(parts[0])
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	lastID, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	for i, event := range events {
+		if i+1 <= lastID {
+			continue
+		}
+
+		fmt.Fprintf(w, "event: %s\nid: %d\n", event.Type, i+1)
+		for _, line := range strings.Split(event.Data, "\n") {
+			fmt.Fprintf(w, "data: %s\n", line)
+		}
+		fmt.Fprint(w, "\n")
+	}
+
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	select {
+	case <-r.Context().Done():
+	case <-time.After(streamLinger):
+	}
+}
+
+// streamEvents finishes a prediction and returns the events of its stream
+func (s *Server) streamEvents(id string) ([]replicate.SSEEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.predictions[id]
+	if !ok || p.prediction.URLs["stream"] == "" {
+		return nil, false
+	}
+
+	if !p.prediction.Status.Terminated() {
+		s.complete(p)
+	}
+
+	events := []replicate.SSEEvent{}
+	if p.prediction.Logs != nil {
+		for _, line := range strings.SplitAfter(*p.prediction.Logs, "\n") {
+			if line != "" {
+				events = append(events, replicate.SSEEvent{Type: replicate.SSETypeLogs, Data: strings.TrimSuffix(line, "\n")})
+			}
+		}
+	}
+
+	switch p.prediction.Status {
+	case replicate.Succeeded:
+		if items, ok := p.prediction.Output.([]interface{}); ok {
+			for _, item := range items {
+				events = append(events, replicate.SSEEvent{Type: replicate.SSETypeOutput, Data: fmt.Sprint(item)})
+			}
+		} else if text, ok := p.prediction.Output.(string); ok {
+			events = append(events, replicate.SSEEvent{Type: replicate.SSETypeOutput, Data: text})
+		} else {
+			data, _ := json.Marshal(p.prediction.Output)
+			events = append(events, replicate.SSEEvent{Type: replicate.SSETypeOutput, Data: string(data)})
+		}
+	case replicate.Failed:
+		data, _ := json.Marshal(map[string]interface{}{"detail": p.prediction.Error})
+		events = append(events, replicate.SSEEvent{Type: replicate.SSETypeError, Data: string(data)})
+	}
+	events = append(events, replicate.SSEEvent{Type: replicate.SSETypeDone, Data: "{}"})
+
+	return events, true
+}
@@ -0,0 +1,247 @@
+// Package mockserver implements a stand-in for the Replicate API,
+// for running the CLI and its tests without a network connection.
+//
+// It serves models with schemas, predictions and trainings that
+// progress through their statuses as they're polled, server-sent event
+// streams, deployments, hardware, the account, files and the default
+// webhook secret. IDs and timestamps are deterministic, so the same
+// requests always get the same responses.
+//
+// Point the CLI at it with REPLICATE_BASE_URL:
+//
+//	s := httptest.NewServer(mockserver.New())
+//	os.Setenv("REPLICATE_BASE_URL", s.URL)
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Epoch is the time of the mock server's clock when it starts
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Server is a mock Replicate API server
+type Server struct {
+	// Token is the API token requests must have. Any token is accepted if it's empty.
+	Token string
+
+	// Steps is the number of times a prediction or training is fetched
+	// before it finishes. They finish as soon as they're created if it's zero.
+	Steps int
+
+	// PageSize is the number of results in each page of a list, or 0 for a single page
+	PageSize int
+
+	mu          sync.Mutex
+	clock       time.Time
+	ids         map[string]int
+	account     replicate.Account
+	hardware    []replicate.Hardware
+	models      map[string]*model
+	deployments map[string]*replicate.Deployment
+	predictions map[string]*run
+	trainings   map[string]*run
+	files       map[string]*file
+	order       map[string][]string
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithSteps sets the number of times a prediction or training is fetched before it finishes
+func WithSteps(steps int) Option {
+	return func(s *Server) {
+		s.Steps = steps
+	}
+}
+
+// WithToken sets the API token that requests must have
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.Token = token
+	}
+}
+
+// WithPageSize sets the number of results in each page of a list
+func WithPageSize(size int) Option {
+	return func(s *Server) {
+		s.PageSize = size
+	}
+}
+
+// New returns a server with the fixtures described in fixtures.go
+func New(options ...Option) *Server {
+	s := &Server{
+		Steps:       2,
+		clock:       Epoch,
+		ids:         map[string]int{},
+		models:      map[string]*model{},
+		deployments: map[string]*replicate.Deployment{},
+		predictions: map[string]*run{},
+		trainings:   map[string]*run{},
+		files:       map[string]*file{},
+		order:       map[string][]string{},
+	}
+	s.loadFixtures()
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// ServeHTTP routes requests like the Replicate API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, "You did not pass a valid authentication token")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1"), "/")
+	parts := strings.Split(path, "/")
+
+	// Streams lock the server themselves, so they can stay open
+	if parts[0] == "stream" {
+		s.serveStream(w, r, parts[1:])
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	route := r.Method + " " + parts[0]
+	switch {
+	case route == "GET account" && len(parts) == 1:
+		writeJSON(w, http.StatusOK, s.account)
+	case route == "GET hardware" && len(parts) == 1:
+		writeJSON(w, http.StatusOK, s.hardware)
+	case route == "GET webhooks" && path == "webhooks/default/secret":
+		writeJSON(w, http.StatusOK, replicate.WebhookSigningSecret{Key: WebhookSecret})
+	case parts[0] == "models":
+		s.serveModels(w, r, parts[1:])
+	case parts[0] == "deployments":
+		s.serveDeployments(w, r, parts[1:])
+	case parts[0] == "predictions":
+		s.servePredictions(w, r, parts[1:])
+	case parts[0] == "trainings":
+		s.serveTrainings(w, r, parts[1:])
+	case parts[0] == "files":
+		s.serveFiles(w, r, parts[1:])
+	case parts[0] == "outputs":
+		s.serveOutput(w, r, parts[1:])
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// now advances the clock by a second and returns it,
+// so timestamps are deterministic and increasing
+func (s *Server) now() time.Time {
+	s.clock = s.clock.Add(time.Second)
+	return s.clock
+}
+
+func (s *Server) timestamp() string {
+	return s.now().Format(time.RFC3339)
+}
+
+// newID returns the next ID for a kind of resource, like "mockp00001" for a prediction.
+// Version IDs are 64 hex digits, like real ones.
+func (s *Server) newID(kind string) string {
+	s.ids[kind]++
+	if kind == "version" {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("version %d", s.ids[kind])))
+		return hex.EncodeToString(sum[:])
+	}
+	return fmt.Sprintf("%s%05d", idPrefixes[kind], s.ids[kind])
+}
+
+var idPrefixes = map[string]string{
+	"prediction": "mockp",
+	"training":   "mockt",
+	"file":       "mockf",
+}
+
+// track records the order a resource was created in, for lists
+func (s *Server) track(kind string, id string) {
+	s.order[kind] = append(s.order[kind], id)
+}
+
+// writePage writes the results from the cursor in the request,
+// with a URL for the next page if there are more.
+// The URL is relative to the base URL, which is what replicate.Paginate expects.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, results []interface{}) {
+	start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	if start < 0 || start > len(results) {
+		start = len(results)
+	}
+
+	end := len(results)
+	if s.PageSize > 0 && start+s.PageSize < end {
+		end = start + s.PageSize
+	}
+
+	page := map[string]interface{}{
+		"results":  results[start:end],
+		"next":     nil,
+		"previous": nil,
+	}
+	if end < len(results) {
+		query := r.URL.Query()
+		query.Set("cursor", strconv.Itoa(end))
+		page["next"] = strings.TrimPrefix(r.URL.Path, "/v1") + "?" + query.Encode()
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// baseURL returns the URL of the server the request was made to
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, replicate.APIError{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
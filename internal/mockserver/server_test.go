@@ -0,0 +1,206 @@
+package mockserver_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/replicate/replicate-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/mockserver"
+	"github.com/replicate/cli/internal/util"
+)
+
+func newClient(t *testing.T, options ...mockserver.Option) (*replicate.Client, *httptest.Server) {
+	server := httptest.NewServer(mockserver.New(options...))
+	t.Cleanup(server.Close)
+
+	r8, err := replicate.NewClient(
+		replicate.WithBaseURL(server.URL),
+		replicate.WithToken("test-token"),
+	)
+	assert.NoError(t, err)
+
+	return r8, server
+}
+
+func TestAccountAndHardware(t *testing.T) {
+	r8, _ := newClient(t)
+	ctx := context.Background()
+
+	account, err := r8.GetCurrentAccount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, mockserver.Username, account.Username)
+
+	hardware, err := r8.ListHardware(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", (*hardware)[0].SKU)
+
+	secret, err := r8.GetDefaultWebhookSecret(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, mockserver.WebhookSecret, secret.Key)
+}
+
+func TestToken(t *testing.T) {
+	r8, _ := newClient(t, mockserver.WithToken("other-token"))
+
+	_, err := r8.GetCurrentAccount(context.Background())
+	assert.ErrorContains(t, err, "valid authentication token")
+}
+
+func TestPredictionProgression(t *testing.T) {
+	r8, _ := newClient(t)
+	ctx := context.Background()
+
+	model, err := r8.GetModel(ctx, "stability-ai", "sdxl")
+	assert.NoError(t, err)
+
+	input, _, err := util.GetSchemas(*model.LatestVersion)
+	assert.NoError(t, err)
+	assert.Contains(t, input.Properties, "num_outputs")
+
+	prediction, err := r8.CreatePrediction(ctx, model.LatestVersion.ID, replicate.PredictionInput{"prompt": "a corgi", "num_outputs": 2}, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "mockp00001", prediction.ID)
+	assert.Equal(t, replicate.Starting, prediction.Status)
+
+	prediction, err = r8.GetPrediction(ctx, prediction.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, replicate.Processing, prediction.Status)
+	assert.Equal(t, 0.5, prediction.Progress().Percentage)
+
+	prediction, err = r8.GetPrediction(ctx, prediction.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Len(t, prediction.Output, 2)
+	assert.NotNil(t, prediction.Metrics.PredictTime)
+
+	// Output files are served too
+	resp, err := http.Get(prediction.Output.([]interface{})[0].(string))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+
+	page, err := r8.ListPredictions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "mockp00001", page.Results[0].ID)
+}
+
+func TestPredictionFailureAndCancel(t *testing.T) {
+	r8, _ := newClient(t, mockserver.WithSteps(0))
+	ctx := context.Background()
+
+	prediction, err := r8.CreatePredictionWithModel(ctx, "replicate", "hello-world", replicate.PredictionInput{"text": "world"}, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, "hello world", prediction.Output)
+
+	prediction, err = r8.CreatePredictionWithDeployment(ctx, mockserver.Username, "hello-world", replicate.PredictionInput{"text": "world", mockserver.FailInput: true}, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, replicate.Failed, prediction.Status)
+	assert.Equal(t, "mock failure", prediction.Error)
+
+	r8, _ = newClient(t)
+	prediction, err = r8.CreatePredictionWithModel(ctx, "replicate", "hello-world", replicate.PredictionInput{"text": "world"}, nil, false)
+	assert.NoError(t, err)
+
+	prediction, err = r8.CancelPrediction(ctx, prediction.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, replicate.Canceled, prediction.Status)
+}
+
+func TestStream(t *testing.T) {
+	r8, _ := newClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prediction, err := r8.CreatePredictionWithModel(ctx, "meta", "llama-2-7b-chat", replicate.PredictionInput{"prompt": "tell me a joke"}, nil, true)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, prediction.URLs["stream"])
+
+	events, errs := r8.StreamPrediction(ctx, prediction)
+
+	var output strings.Builder
+	for done := false; !done; {
+		select {
+		case event := <-events:
+			switch event.Type {
+			case replicate.SSETypeOutput:
+				output.WriteString(event.Data)
+			case replicate.SSETypeDone:
+				done = true
+			}
+		case err := <-errs:
+			assert.NoError(t, err)
+			done = true
+		}
+	}
+	assert.Equal(t, "You said: tell me a joke", output.String())
+}
+
+func TestTraining(t *testing.T) {
+	r8, _ := newClient(t, mockserver.WithSteps(0))
+	ctx := context.Background()
+
+	model, err := r8.GetModel(ctx, "replicate", "hello-world")
+	assert.NoError(t, err)
+
+	destination := mockserver.Username + "/hello-world-fine-tuned"
+	training, err := r8.CreateTraining(ctx, "replicate", "hello-world", model.LatestVersion.ID, destination, replicate.TrainingInput{"text": "data"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, training.Status)
+
+	trained, err := r8.GetModel(ctx, mockserver.Username, "hello-world-fine-tuned")
+	assert.NoError(t, err)
+	assert.Equal(t, destination+":"+trained.LatestVersion.ID, training.Output.(map[string]interface{})["version"])
+
+	_, err = r8.CreateTraining(ctx, "replicate", "hello-world", model.LatestVersion.ID, "test-user/missing", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestFiles(t *testing.T) {
+	r8, _ := newClient(t, mockserver.WithPageSize(1))
+	ctx := context.Background()
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		_, err := r8.CreateFileFromBytes(ctx, []byte("contents of "+name), &replicate.CreateFileOptions{
+			Filename:    name,
+			ContentType: "text/plain",
+			Metadata:    util.UploadMetadata,
+		})
+		assert.NoError(t, err)
+	}
+
+	page, err := r8.ListFiles(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, page.Results, 1)
+
+	names := []string{}
+	results, errs := replicate.Paginate(ctx, r8, page)
+	for files := range results {
+		for _, file := range files {
+			names = append(names, file.Name)
+		}
+	}
+	assert.NoError(t, <-errs)
+	assert.Equal(t, []string{"c.txt", "b.txt", "a.txt"}, names)
+
+	file, err := r8.GetFile(ctx, "mockf00001")
+	assert.NoError(t, err)
+	assert.Equal(t, util.UploadMetadata, file.Metadata)
+
+	resp, err := http.Get(file.URLs["get"])
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.True(t, bytes.Equal([]byte("contents of a.txt"), body))
+
+	assert.NoError(t, r8.DeleteFile(ctx, file.ID))
+	_, err = r8.GetFile(ctx, file.ID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+// Package pricing estimates what predictions cost from the hardware they run on.
+package pricing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/replicate/cli/internal/config"
+)
+
+// defaultPrices are Replicate's public prices in USD per second of hardware time.
+// Prices change, so they can be overridden with a prices file.
+var defaultPrices = map[string]float64{
+	"cpu":               0.000100,
+	"gpu-t4":            0.000225,
+	"gpu-a40-small":     0.000575,
+	"gpu-a40-large":     0.000725,
+	"gpu-l40s":          0.000975,
+	"gpu-l40s-2x":       0.001950,
+	"gpu-l40s-4x":       0.003900,
+	"gpu-l40s-8x":       0.007800,
+	"gpu-a100-large":    0.001400,
+	"gpu-a100-large-2x": 0.002800,
+	"gpu-a100-large-4x": 0.005600,
+	"gpu-a100-large-8x": 0.011200,
+	"gpu-h100":          0.001525,
+	"gpu-h100-2x":       0.003050,
+	"gpu-h100-4x":       0.006100,
+	"gpu-h100-8x":       0.012200,
+}
+
+// Table is the price of each hardware SKU,
+// and the hardware that models without deployments run on
+type Table struct {
+	// Hardware is the price of each SKU in USD per second
+	Hardware map[string]float64 `yaml:"hardware" json:"hardware"`
+
+	// Models is the SKU each model runs on, like stability-ai/sdxl: gpu-a40-large
+	Models map[string]string `yaml:"models" json:"models"`
+}
+
+// DefaultPath returns where prices are read from if no file is given,
+// alongside the CLI's config
+func DefaultPath() string {
+	if config.ConfigFilePath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(config.ConfigFilePath), "prices.yaml")
+}
+
+// Load returns the default prices, overridden by a YAML or JSON prices file.
+// If path is empty, the file at DefaultPath is used if there is one.
+func Load(path string) (*Table, error) {
+	table := &Table{
+		Hardware: map[string]float64{},
+		Models:   map[string]string{},
+	}
+	for sku, price := range defaultPrices {
+		table.Hardware[sku] = price
+	}
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+	if path == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return table, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prices: %w", err)
+	}
+
+	var overrides Table
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse prices in %s: %w", path, err)
+	}
+	for sku, price := range overrides.Hardware {
+		table.Hardware[sku] = price
+	}
+	for model, sku := range overrides.Models {
+		table.Models[model] = sku
+	}
+
+	return table, nil
+}
+
+// Price returns the price of a SKU in USD per second
+func (t *Table) Price(sku string) (float64, bool) {
+	price, ok := t.Hardware[sku]
+	return price, ok
+}
+
+// Cost returns the cost of running on a SKU for a number of seconds
+func (t *Table) Cost(sku string, seconds float64) (float64, bool) {
+	price, ok := t.Price(sku)
+	if !ok {
+		return 0, false
+	}
+	return price * seconds, true
+}
@@ -0,0 +1,49 @@
+package pricing_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/config"
+	"github.com/replicate/cli/internal/pricing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	config.ConfigFilePath = filepath.Join(dir, "hosts")
+
+	// Without a prices file, the defaults are used
+	table, err := pricing.Load("")
+	assert.NoError(t, err)
+	cost, ok := table.Cost("gpu-t4", 10)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.00225, cost, 1e-12)
+	_, ok = table.Price("gpu-unknown")
+	assert.False(t, ok)
+
+	// The default file overrides them
+	assert.NoError(t, os.WriteFile(pricing.DefaultPath(), []byte("hardware:\n  gpu-t4: 0.001\n  gpu-custom: 0.002\nmodels:\n  stability-ai/sdxl: gpu-t4\n"), 0o644))
+	table, err = pricing.Load("")
+	assert.NoError(t, err)
+	price, _ := table.Price("gpu-t4")
+	assert.Equal(t, 0.001, price)
+	price, _ = table.Price("gpu-custom")
+	assert.Equal(t, 0.002, price)
+	price, _ = table.Price("cpu")
+	assert.Equal(t, 0.0001, price)
+	assert.Equal(t, "gpu-t4", table.Models["stability-ai/sdxl"])
+
+	// So does a file that's given, which can be JSON
+	path := filepath.Join(dir, "prices.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"hardware": {"cpu": 0.5}}`), 0o644))
+	table, err = pricing.Load(path)
+	assert.NoError(t, err)
+	price, _ = table.Price("cpu")
+	assert.Equal(t, 0.5, price)
+
+	_, err = pricing.Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,376 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/cli/browser"
+)
+
+var (
+	baseStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("240"))
+	helpStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// Action is a command that can be run on the selected row of a Table
+type Action struct {
+	// Key is the key that runs the action
+	Key string
+
+	// Help is a short description shown in the help line
+	Help string
+
+	// Confirm requires the key to be pressed twice
+	Confirm bool
+
+	// Run returns a command for the selected row.
+	// The command should send an ActionResult when it's done.
+	Run func(row table.Row) tea.Cmd
+}
+
+// ActionResult is sent by an action when it finishes
+type ActionResult struct {
+	Message string
+	Err     error
+
+	// Remove removes the row the action was run on
+	Remove bool
+	ID     string
+}
+
+// Result returns a command that runs fn and reports the result
+func Result(id string, fn func() (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		message, err := fn()
+		return ActionResult{ID: id, Message: message, Err: err}
+	}
+}
+
+// Exec returns a command that runs this executable with args,
+// handing it the terminal until it exits
+func Exec(args ...string) tea.Cmd {
+	executable, err := os.Executable()
+	if err != nil {
+		return func() tea.Msg { return ActionResult{Err: err} }
+	}
+
+	c := exec.Command(executable, args...) //nolint:gosec
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return ActionResult{Err: err}
+	})
+}
+
+// Table is an interactive table with sorting, searching and per-row actions.
+// The first column of every row is its ID.
+type Table struct {
+	table   table.Model
+	columns []table.Column
+	rows    []table.Row
+
+	url     func(row table.Row) string
+	actions []Action
+
+	sortColumn int
+	sortDesc   bool
+	search     textinput.Model
+
+	pending string
+	message string
+	err     error
+}
+
+// TableOption configures a Table
+type TableOption func(*Table)
+
+// WithURL sets the link that's opened in the browser when enter is pressed
+func WithURL(url func(row table.Row) string) TableOption {
+	return func(t *Table) {
+		t.url = url
+	}
+}
+
+// WithActions adds actions that can be run on the selected row
+func WithActions(actions ...Action) TableOption {
+	return func(t *Table) {
+		t.actions = append(t.actions, actions...)
+	}
+}
+
+// NewTableModel returns a focused table with the given columns and height,
+// styled like the other tables in the CLI
+func NewTableModel(columns []table.Column, height int) table.Model {
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(height),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+	t.SetStyles(s)
+
+	return t
+}
+
+// RenderTable renders a table made by NewTableModel with its border
+func RenderTable(t table.Model) string {
+	return baseStyle.Render(t.View())
+}
+
+// NewTable returns a table with the given columns and rows
+func NewTable(columns []table.Column, rows []table.Row, opts ...TableOption) *Table {
+	t := &Table{
+		columns:    columns,
+		rows:       rows,
+		sortColumn: -1,
+	}
+
+	t.table = NewTableModel(columns, 30)
+
+	t.search = textinput.New()
+	t.search.Prompt = "/"
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	t.refresh()
+
+	return t
+}
+
+// Run displays the table until the user quits
+func (t *Table) Run() error {
+	// Keep the browser from writing over the table
+	browser.Stdout = io.Discard
+	browser.Stderr = io.Discard
+
+	_, err := tea.NewProgram(t).Run()
+	return err
+}
+
+func (t *Table) Init() tea.Cmd { return nil }
+
+func (t *Table) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ActionResult:
+		t.message, t.err = msg.Message, msg.Err
+		if msg.Remove && msg.Err == nil {
+			t.remove(msg.ID)
+		}
+		return t, nil
+	case tea.KeyMsg:
+		if t.search.Focused() {
+			switch msg.String() {
+			case "enter":
+				t.search.Blur()
+				t.table.Focus()
+				return t, nil
+			case "esc":
+				t.search.SetValue("")
+				t.search.Blur()
+				t.table.Focus()
+				t.refresh()
+				return t, nil
+			}
+
+			var cmd tea.Cmd
+			t.search, cmd = t.search.Update(msg)
+			t.refresh()
+			return t, cmd
+		}
+
+		key := msg.String()
+		pending := t.pending
+		t.pending = ""
+
+		switch key {
+		case "q", "ctrl+c":
+			return t, tea.Quit
+		case "esc":
+			if t.table.Focused() {
+				t.table.Blur()
+			} else {
+				t.table.Focus()
+			}
+			return t, nil
+		case "/":
+			t.search.Focus()
+			t.table.Blur()
+			return t, textinput.Blink
+		case "enter":
+			row := t.table.SelectedRow()
+			if len(row) == 0 || t.url == nil {
+				return t, nil
+			}
+			url := t.url(row)
+			return t, Result(row[0], func() (string, error) {
+				if err := browser.OpenURL(url); err != nil {
+					return "", fmt.Errorf("failed to open browser: %w", err)
+				}
+				return "Opened " + url, nil
+			})
+		case "y":
+			row := t.table.SelectedRow()
+			if len(row) == 0 {
+				return t, nil
+			}
+			_, _ = osc52.New(row[0]).WriteTo(os.Stderr)
+			t.message, t.err = fmt.Sprintf("Copied %s to clipboard", row[0]), nil
+			return t, nil
+		}
+
+		if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(t.columns) {
+			if t.sortColumn == n-1 {
+				t.sortDesc = !t.sortDesc
+			} else {
+				t.sortColumn, t.sortDesc = n-1, false
+			}
+			t.refresh()
+			return t, nil
+		}
+
+		for _, action := range t.actions {
+			if action.Key != key {
+				continue
+			}
+
+			row := t.table.SelectedRow()
+			if len(row) == 0 {
+				return t, nil
+			}
+
+			if action.Confirm && pending != key {
+				t.pending = key
+				t.message, t.err = fmt.Sprintf("Press %s again to %s %s", key, strings.ToLower(action.Help), row[0]), nil
+				return t, nil
+			}
+
+			t.message, t.err = "", nil
+			return t, action.Run(row)
+		}
+	}
+
+	var cmd tea.Cmd
+	t.table, cmd = t.table.Update(msg)
+	return t, cmd
+}
+
+func (t *Table) View() string {
+	var b strings.Builder
+
+	if t.search.Focused() || t.search.Value() != "" {
+		b.WriteString(t.search.View() + "\n")
+	}
+
+	b.WriteString(RenderTable(t.table) + "\n")
+
+	switch {
+	case t.err != nil:
+		b.WriteString(errorStyle.Render(t.err.Error()) + "\n")
+	case t.message != "":
+		b.WriteString(t.message + "\n")
+	}
+
+	help := []string{"/ search", "1-9 sort", "y copy ID"}
+	if t.url != nil {
+		help = append(help, "enter open")
+	}
+	for _, action := range t.actions {
+		help = append(help, fmt.Sprintf("%s %s", action.Key, strings.ToLower(action.Help)))
+	}
+	help = append(help, "q quit")
+	b.WriteString(helpStyle.Render(strings.Join(help, " • ")) + "\n")
+
+	return b.String()
+}
+
+// refresh filters and sorts the rows, and updates the column headers
+func (t *Table) refresh() {
+	query := strings.ToLower(t.search.Value())
+
+	rows := []table.Row{}
+	for _, row := range t.rows {
+		if query == "" || rowContains(row, query) {
+			rows = append(rows, row)
+		}
+	}
+
+	if t.sortColumn >= 0 {
+		col, desc := t.sortColumn, t.sortDesc
+		sort.SliceStable(rows, func(i, j int) bool {
+			if desc {
+				return lessCell(rows[j][col], rows[i][col])
+			}
+			return lessCell(rows[i][col], rows[j][col])
+		})
+	}
+
+	columns := make([]table.Column, len(t.columns))
+	copy(columns, t.columns)
+	if t.sortColumn >= 0 {
+		arrow := " ▲"
+		if t.sortDesc {
+			arrow = " ▼"
+		}
+		columns[t.sortColumn].Title += arrow
+	}
+
+	// Columns must be set before rows, which are rendered with them
+	t.table.SetRows([]table.Row{})
+	t.table.SetColumns(columns)
+	t.table.SetRows(rows)
+	if t.table.Cursor() >= len(rows) {
+		t.table.SetCursor(max(len(rows)-1, 0))
+	}
+}
+
+func (t *Table) remove(id string) {
+	for i, row := range t.rows {
+		if len(row) > 0 && row[0] == id {
+			t.rows = append(t.rows[:i], t.rows[i+1:]...)
+			break
+		}
+	}
+	t.refresh()
+}
+
+func rowContains(row table.Row, query string) bool {
+	for _, cell := range row {
+		if strings.Contains(strings.ToLower(cell), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// lessCell compares cells numerically if both are numbers, and as text otherwise
+func lessCell(a, b string) bool {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+	return a < b
+}
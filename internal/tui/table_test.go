@@ -0,0 +1,76 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/tui"
+)
+
+func TestTable(t *testing.T) {
+	columns := []table.Column{
+		{Title: "Name", Width: 20},
+		{Title: "Runs", Width: 10},
+	}
+	rows := []table.Row{
+		{"acme/hotdog", "10"},
+		{"acme/burger", "9"},
+		{"acme/pizza", "100"},
+	}
+
+	key := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	t.Run("sorts numerically", func(t *testing.T) {
+		tbl := tui.NewTable(columns, rows)
+		tbl.Update(key("2"))
+		assert.Contains(t, tbl.View(), "Runs ▲")
+		assert.Regexp(t, `(?s)burger.*hotdog.*pizza`, tbl.View())
+
+		tbl.Update(key("2"))
+		assert.Regexp(t, `(?s)pizza.*hotdog.*burger`, tbl.View())
+	})
+
+	t.Run("searches", func(t *testing.T) {
+		tbl := tui.NewTable(columns, rows)
+		tbl.Update(key("/"))
+		for _, r := range "pi" {
+			tbl.Update(key(string(r)))
+		}
+
+		view := tbl.View()
+		assert.Contains(t, view, "pizza")
+		assert.NotContains(t, view, "hotdog")
+
+		tbl.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Contains(t, tbl.View(), "hotdog")
+	})
+
+	t.Run("confirms actions", func(t *testing.T) {
+		ran := 0
+		tbl := tui.NewTable(columns, rows, tui.WithActions(tui.Action{
+			Key:     "x",
+			Help:    "Delete",
+			Confirm: true,
+			Run: func(row table.Row) tea.Cmd {
+				ran++
+				return func() tea.Msg {
+					return tui.ActionResult{ID: row[0], Remove: true}
+				}
+			},
+		}))
+
+		_, cmd := tbl.Update(key("x"))
+		assert.Nil(t, cmd)
+		assert.Contains(t, tbl.View(), "Press x again to delete acme/hotdog")
+
+		_, cmd = tbl.Update(key("x"))
+		assert.Equal(t, 1, ran)
+		tbl.Update(cmd())
+		assert.NotContains(t, tbl.View(), "hotdog")
+	})
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"strings"
+)
+
+// DiffOp is the kind of change to a line in a diff
+type DiffOp rune
+
+const (
+	DiffEqual  DiffOp = ' '
+	DiffDelete DiffOp = '-'
+	DiffInsert DiffOp = '+'
+)
+
+// DiffLine is a line in a diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+func (l DiffLine) String() string {
+	return string(l.Op) + l.Text
+}
+
+// Diff returns the line-by-line differences between two texts,
+// using the longest common subsequence of their lines
+func Diff(a, b string) []DiffLine {
+	x := strings.Split(a, "\n")
+	y := strings.Split(b, "\n")
+
+	// lcs[i][j] is the length of the LCS of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := []DiffLine{}
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, DiffLine{DiffEqual, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{DiffDelete, x[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{DiffInsert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, DiffLine{DiffDelete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, DiffLine{DiffInsert, y[j]})
+	}
+
+	return lines
+}
+
+// FormatDiff renders a diff with a header naming each side, like diff -u
+func FormatDiff(nameA, nameB string, lines []DiffLine) string {
+	var b strings.Builder
+	b.WriteString("--- " + nameA + "\n")
+	b.WriteString("+++ " + nameB + "\n")
+	for _, line := range lines {
+		b.WriteString(line.String() + "\n")
+	}
+	return b.String()
+}
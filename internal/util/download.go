@@ -1,91 +1,546 @@
 package util
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/replicate/replicate-go"
+	"github.com/schollz/progressbar/v3"
 	"golang.org/x/sync/errgroup"
 )
 
+// DefaultOutputTemplate names downloaded files after the last segment of their URL
+const DefaultOutputTemplate = "{{.Basename}}"
+
+const (
+	defaultDownloadRetries     = 3
+	defaultDownloadConcurrency = 4
+)
+
+// commonExtensions are preferred over the system MIME table,
+// which lists several extensions for some types in no particular order
+var commonExtensions = map[string]string{
+	"audio/mpeg":       ".mp3",
+	"audio/wav":        ".wav",
+	"image/jpeg":       ".jpg",
+	"image/png":        ".png",
+	"image/webp":       ".webp",
+	"text/plain":       ".txt",
+	"video/mp4":        ".mp4",
+	"application/json": ".json",
+}
+
+// OutputFile is a file referenced by a prediction output
+type OutputFile struct {
+	// Index is the position of the file among all files in the output
+	Index int
+
+	// Key is the location of the file in the output, like "images.0"
+	Key string
+
+	// URL is the HTTP(S) or data URI of the file
+	URL string
+
+	// Basename is the file name, including its extension
+	Basename string
+
+	// Name is the file name, without its extension
+	Name string
+
+	// Ext is the file extension, including the leading dot
+	Ext string
+
+	// PredictionID is the ID of the prediction that produced the file
+	PredictionID string
+}
+
+// DownloadedFile is a file saved by a Downloader
+type DownloadedFile struct {
+	URL    string `json:"url"`
+	Key    string `json:"key"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Downloader saves the files referenced by a prediction output to a directory
+type Downloader struct {
+	// Client is used to fetch files. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// Template is a text/template used to name each file.
+	// It's executed with an OutputFile. Defaults to DefaultOutputTemplate.
+	Template string
+
+	// Retries is the number of times a failed download is retried.
+	// Interrupted downloads are resumed with a Range request.
+	Retries int
+
+	// Progress shows a progress bar for each file on stderr.
+	// Files are downloaded one at a time when enabled.
+	Progress bool
+}
+
+// DownloadPrediction saves the outputs of a prediction to a directory with the default options
 func DownloadPrediction(ctx context.Context, prediction replicate.Prediction, dir string) error {
+	d := &Downloader{}
+	_, err := d.Download(ctx, prediction, dir)
+	return err
+}
+
+// Download saves the outputs of a prediction to a directory.
+//
+// Every URL or data URI in the output is saved as a file.
+// Output that isn't entirely made of files is also written to output.json.
+func (d *Downloader) Download(ctx context.Context, prediction replicate.Prediction, dir string) ([]DownloadedFile, error) {
 	if prediction.ID == "" {
-		return fmt.Errorf("prediction ID is empty")
+		return nil, fmt.Errorf("prediction ID is empty")
 	}
 
 	if prediction.Status != replicate.Succeeded {
-		return fmt.Errorf("prediction is not finished")
+		return nil, fmt.Errorf("prediction is not finished")
 	}
 
 	if prediction.Output == nil {
-		return fmt.Errorf("prediction output is empty")
+		return nil, fmt.Errorf("prediction output is empty")
 	}
 
 	if dir == "" {
-		return fmt.Errorf("directory is empty")
+		return nil, fmt.Errorf("directory is empty")
 	}
 
 	err := os.MkdirAll(dir, 0o755)
 	if err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	if reflect.TypeOf(prediction.Output).Kind() == reflect.Slice {
-		v := reflect.ValueOf(prediction.Output)
-		strings := make([]string, v.Len())
-		for i := 0; i < v.Len(); i++ {
-			strings[i] = v.Index(i).Interface().(string)
-		}
+	files, onlyFiles := CollectOutputFiles(prediction.Output)
+	for i := range files {
+		files[i].PredictionID = prediction.ID
+	}
+
+	paths, err := d.filenames(files, dir)
+	if err != nil {
+		return nil, err
+	}
 
-		g, _ := errgroup.WithContext(ctx)
+	results := make([]DownloadedFile, len(files))
 
-		for _, str := range strings {
-			u, err := url.ParseRequestURI(str)
+	g, ctx := errgroup.WithContext(ctx)
+	if d.Progress {
+		g.SetLimit(1)
+	} else {
+		g.SetLimit(defaultDownloadConcurrency)
+	}
+
+	for i, file := range files {
+		i, file := i, file
+		g.Go(func() error {
+			result, err := d.downloadFile(ctx, file, paths[i])
 			if err != nil {
-				break
+				return err
 			}
+			results[i] = *result
+			return nil
+		})
+	}
 
-			req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-			if err != nil {
-				break
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if !onlyFiles {
+		data, err := json.MarshalIndent(prediction.Output, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal prediction output: %w", err)
+		}
+
+		err = os.WriteFile(filepath.Join(dir, "output.json"), data, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+
+	return results, nil
+}
+
+// CollectOutputFiles returns the files referenced by a prediction output,
+// and whether the output consists of nothing but files.
+//
+// Strings, lists and objects are walked recursively.
+// Object keys are visited in sorted order, so the result is stable.
+func CollectOutputFiles(output interface{}) ([]OutputFile, bool) {
+	files := []OutputFile{}
+	onlyFiles := true
+
+	var walk func(v reflect.Value, key string)
+	walk = func(v reflect.Value, key string) {
+		for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				onlyFiles = false
+				return
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.String:
+			file, ok := parseOutputFile(v.String())
+			if !ok {
+				onlyFiles = false
+				return
+			}
+			file.Index = len(files)
+			file.Key = key
+			files = append(files, *file)
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i), joinKey(key, strconv.Itoa(i)))
+			}
+		case reflect.Map:
+			keys := []string{}
+			values := map[string]reflect.Value{}
+			iter := v.MapRange()
+			for iter.Next() {
+				k := fmt.Sprintf("%v", iter.Key().Interface())
+				keys = append(keys, k)
+				values[k] = iter.Value()
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(values[k], joinKey(key, k))
+			}
+		default:
+			onlyFiles = false
+		}
+	}
+
+	walk(reflect.ValueOf(output), "")
+
+	return files, onlyFiles && len(files) > 0
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// parseOutputFile returns the file referenced by s if it's an HTTP(S) or data URI
+func parseOutputFile(s string) (*OutputFile, bool) {
+	if strings.HasPrefix(s, "data:") {
+		mediaType, _, _ := strings.Cut(strings.TrimPrefix(s, "data:"), ",")
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+
+		ext, ok := commonExtensions[mediaType]
+		if !ok {
+			if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+				ext = exts[0]
+			}
+		}
+
+		return &OutputFile{
+			URL:      s,
+			Basename: "output" + ext,
+			Name:     "output",
+			Ext:      ext,
+		}, true
+	}
+
+	u, err := url.ParseRequestURI(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, false
+	}
+
+	basename := path.Base(u.Path)
+	if basename == "/" || basename == "." {
+		basename = "output"
+	}
+	ext := path.Ext(basename)
+
+	return &OutputFile{
+		URL:      s,
+		Basename: basename,
+		Name:     strings.TrimSuffix(basename, ext),
+		Ext:      ext,
+	}, true
+}
+
+// filenames renders the name template for each file.
+// Names that collide are made unique by appending a counter.
+func (d *Downloader) filenames(files []OutputFile, dir string) ([]string, error) {
+	text := d.Template
+	if text == "" {
+		text = DefaultOutputTemplate
+	}
+
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+
+	seen := map[string]bool{}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, file); err != nil {
+			return nil, fmt.Errorf("failed to render output template for %s: %w", file.Key, err)
+		}
+
+		name := filepath.Clean(buf.String())
+		if name == "." || filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("output template produced invalid file name %q", buf.String())
+		}
+
+		ext := filepath.Ext(name)
+		stem := strings.TrimSuffix(name, ext)
+		for n := 1; seen[name]; n++ {
+			name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+		}
+		seen[name] = true
+
+		paths[i] = filepath.Join(dir, name)
+	}
+
+	return paths, nil
+}
+
+// DownloadFile saves the file at a URL to a path
+func (d *Downloader) DownloadFile(ctx context.Context, url string, dest string) (*DownloadedFile, error) {
+	basename := filepath.Base(dest)
+	ext := filepath.Ext(basename)
+
+	return d.downloadFile(ctx, OutputFile{
+		URL:      url,
+		Basename: basename,
+		Name:     strings.TrimSuffix(basename, ext),
+		Ext:      ext,
+	}, dest)
+}
+
+// downloadFile saves a single file, retrying and resuming failed transfers
+func (d *Downloader) downloadFile(ctx context.Context, file OutputFile, dest string) (*DownloadedFile, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if strings.HasPrefix(file.URL, "data:") {
+		data, err := DecodeDataURI(file.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", file.Key, err)
+		}
+
+		if err := os.WriteFile(dest, data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write file %s: %w", dest, err)
+		}
+
+		return newDownloadedFile(file, dest)
+	}
+
+	retries := d.Retries
+	if retries <= 0 {
+		retries = defaultDownloadRetries
+	}
+
+	partial := dest + ".part"
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
 			}
+		}
+
+		err = d.fetch(ctx, file, partial)
+		if err == nil {
+			break
+		}
+
+		var statusErr *downloadStatusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file %s: %w", file.URL, err)
+	}
+
+	if err := os.Rename(partial, dest); err != nil {
+		return nil, fmt.Errorf("failed to move file to %s: %w", dest, err)
+	}
+
+	return newDownloadedFile(file, dest)
+}
+
+// fetch writes the contents of a file to path,
+// continuing from the end of any existing partial download
+func (d *Downloader) fetch(ctx context.Context, file OutputFile, path string) error {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
-			g.Go(func() error {
-				resp, err := http.DefaultClient.Do(req)
-				if err != nil {
-					return fmt.Errorf("failed to download file %v: %w", u, err)
-				}
-				defer resp.Body.Close()
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
 
-				filename := filepath.Base(u.Path)
-				file, err := os.Create(filepath.Join(dir, filename))
-				if err != nil {
-					return fmt.Errorf("failed to create file %s: %w", filename, err)
-				}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial download is already complete if it's as long as the file.
+		// Otherwise the file changed, or the partial download is corrupt, so start again.
+		if size, ok := rangeSize(resp.Header.Get("Content-Range")); ok && size == offset {
+			return nil
+		}
+		resp.Body.Close()
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return d.fetch(ctx, file, path)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		offset = 0
+		flags |= os.O_TRUNC
+	default:
+		return &downloadStatusError{StatusCode: resp.StatusCode}
+	}
 
-				_, err = io.Copy(file, resp.Body)
-				if err != nil {
-					return fmt.Errorf("failed to write file %s: %w", filename, err)
-				}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-				return nil
-			})
+	var w io.Writer = f
+	if d.Progress {
+		total := int64(-1)
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
 		}
+		bar := progressbar.DefaultBytes(total, file.Basename)
+		_ = bar.Set64(offset)
+		defer bar.Close()
+		w = io.MultiWriter(f, bar)
+	}
 
-		return g.Wait()
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return fmt.Errorf("expected %d bytes but got %d", resp.ContentLength, n)
+	}
+
+	return nil
+}
+
+// rangeSize returns the size of a file from a Content-Range header like "bytes */1234"
+func rangeSize(header string) (int64, bool) {
+	_, size, ok := strings.Cut(header, "/")
+	if !ok || size == "*" {
+		return 0, false
 	}
 
-	bytes, err := json.Marshal(prediction.Output)
+	n, err := strconv.ParseInt(size, 10, 64)
 	if err != nil {
-		return fmt.Errorf("failed to marshal prediction output: %w", err)
+		return 0, false
 	}
 
-	return os.WriteFile(filepath.Join(dir, "output.json"), bytes, 0o644)
+	return n, true
+}
+
+type downloadStatusError struct {
+	StatusCode int
+}
+
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *downloadStatusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newDownloadedFile describes a saved file, including its SHA-256 checksum
+func newDownloadedFile(file OutputFile, path string) (*DownloadedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute checksum of %s: %w", path, err)
+	}
+
+	return &DownloadedFile{
+		URL:    file.URL,
+		Key:    file.Key,
+		Path:   path,
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// DecodeDataURI returns the contents of a data URI
+func DecodeDataURI(uri string) ([]byte, error) {
+	header, data, found := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !found {
+		return nil, fmt.Errorf("invalid data URI")
+	}
+
+	if strings.HasSuffix(header, ";base64") {
+		return base64.StdEncoding.DecodeString(data)
+	}
+
+	decoded, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(decoded), nil
 }
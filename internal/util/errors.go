@@ -0,0 +1,224 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/replicate/replicate-go"
+)
+
+// ErrorKind classifies errors so scripts can tell them apart
+type ErrorKind string
+
+const (
+	KindError              ErrorKind = "error"
+	KindInvalidInput       ErrorKind = "invalid_input"
+	KindAuth               ErrorKind = "auth"
+	KindNotFound           ErrorKind = "not_found"
+	KindPredictionFailed   ErrorKind = "prediction_failed"
+	KindPredictionCanceled ErrorKind = "prediction_canceled"
+	KindNetwork            ErrorKind = "network"
+	KindTimeout            ErrorKind = "timeout"
+	KindInterrupted        ErrorKind = "interrupted"
+)
+
+// ExitCode returns the exit code for a kind of error.
+// These are documented in the README, so don't change them.
+func (k ErrorKind) ExitCode() int {
+	switch k {
+	case KindInvalidInput:
+		return 2
+	case KindAuth:
+		return 3
+	case KindNotFound:
+		return 4
+	case KindPredictionFailed:
+		return 5
+	case KindPredictionCanceled:
+		return 6
+	case KindNetwork:
+		return 7
+	case KindTimeout:
+		return 8
+	case KindInterrupted:
+		// Like shells, for a command stopped by SIGINT
+		return 130
+	default:
+		return 1
+	}
+}
+
+// Error is an error of a specific kind
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// InvalidInputf returns an error for invalid arguments, flags or inputs
+func InvalidInputf(format string, a ...interface{}) error {
+	return &Error{Kind: KindInvalidInput, Err: fmt.Errorf(format, a...)}
+}
+
+// InvalidInput marks an error as caused by invalid arguments, flags or inputs,
+// unless it already has a kind, like an upload that failed with a network error
+func InvalidInput(err error) error {
+	if Kind(err) != KindError {
+		return err
+	}
+	return &Error{Kind: KindInvalidInput, Err: err}
+}
+
+// TimedOutf returns an error for something that didn't finish before a deadline
+func TimedOutf(format string, a ...interface{}) error {
+	return &Error{Kind: KindTimeout, Err: fmt.Errorf(format, a...)}
+}
+
+// Interruptedf returns an error for a command stopped with Ctrl-C
+func Interruptedf(format string, a ...interface{}) error {
+	return &Error{Kind: KindInterrupted, Err: fmt.Errorf(format, a...)}
+}
+
+// AuthErrorf returns an error for missing or invalid credentials
+func AuthErrorf(format string, a ...interface{}) error {
+	return &Error{Kind: KindAuth, Err: fmt.Errorf(format, a...)}
+}
+
+// PredictionError is returned when a prediction or training finishes without succeeding
+type PredictionError struct {
+	Prediction *replicate.Prediction
+	Training   bool
+}
+
+func (e *PredictionError) Error() string {
+	noun := "prediction"
+	if e.Training {
+		noun = "training"
+	}
+
+	if e.Prediction.Status == replicate.Canceled {
+		return fmt.Sprintf("%s %s was canceled", noun, e.Prediction.ID)
+	}
+	if e.Prediction.Error != nil {
+		return fmt.Sprintf("%s %s failed: %v", noun, e.Prediction.ID, e.Prediction.Error)
+	}
+	return fmt.Sprintf("%s %s failed", noun, e.Prediction.ID)
+}
+
+// CheckPrediction returns a PredictionError if a prediction failed or was canceled
+func CheckPrediction(prediction *replicate.Prediction) error {
+	switch prediction.Status {
+	case replicate.Failed, replicate.Canceled:
+		return &PredictionError{Prediction: prediction}
+	default:
+		return nil
+	}
+}
+
+// CheckTraining returns a PredictionError if a training failed or was canceled
+func CheckTraining(training *replicate.Training) error {
+	switch training.Status {
+	case replicate.Failed, replicate.Canceled:
+		return &PredictionError{Prediction: (*replicate.Prediction)(training), Training: true}
+	default:
+		return nil
+	}
+}
+
+// Kind returns the kind of an error,
+// from the first Error, PredictionError or API error it wraps
+func Kind(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+
+	// Commands' contexts are only canceled by interrupts
+	if errors.Is(err, context.Canceled) {
+		return KindInterrupted
+	}
+
+	var predictionErr *PredictionError
+	if errors.As(err, &predictionErr) {
+		if predictionErr.Prediction.Status == replicate.Canceled {
+			return KindPredictionCanceled
+		}
+		return KindPredictionFailed
+	}
+
+	var apiErr *replicate.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return KindAuth
+		case http.StatusNotFound:
+			return KindNotFound
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return KindInvalidInput
+		default:
+			return KindError
+		}
+	}
+
+	// Includes the *url.Error returned by HTTP clients
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return KindNetwork
+	}
+
+	return KindError
+}
+
+// ErrorEnvelope is how errors are emitted as JSON
+type ErrorEnvelope struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Kind         ErrorKind `json:"kind"`
+	Message      string    `json:"message"`
+	ExitCode     int       `json:"exit_code"`
+	Status       int       `json:"status,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Detail       string    `json:"detail,omitempty"`
+	PredictionID string    `json:"prediction_id,omitempty"`
+}
+
+// NewErrorEnvelope describes an error, with the details of the API error
+// or prediction that caused it
+func NewErrorEnvelope(err error) ErrorEnvelope {
+	kind := Kind(err)
+	detail := ErrorDetail{
+		Kind:     kind,
+		Message:  err.Error(),
+		ExitCode: kind.ExitCode(),
+	}
+
+	var apiErr *replicate.APIError
+	if errors.As(err, &apiErr) {
+		detail.Status = apiErr.Status
+		detail.Title = apiErr.Title
+		detail.Detail = apiErr.Detail
+	}
+
+	var predictionErr *PredictionError
+	if errors.As(err, &predictionErr) {
+		detail.PredictionID = predictionErr.Prediction.ID
+	}
+
+	return ErrorEnvelope{Error: detail}
+}
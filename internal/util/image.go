@@ -0,0 +1,250 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoder
+)
+
+// ImageProtocol is a terminal graphics protocol for displaying images inline
+type ImageProtocol string
+
+const (
+	ImageProtocolNone  ImageProtocol = ""
+	ImageProtocolKitty ImageProtocol = "kitty"
+	ImageProtocolITerm ImageProtocol = "iterm"
+	ImageProtocolSixel ImageProtocol = "sixel"
+)
+
+const (
+	maxInlineImageBytes = 20 << 20
+	maxSixelWidth       = 800
+)
+
+// DetectImageProtocol returns the graphics protocol supported by the terminal.
+//
+// Terminals don't reliably advertise sixel support,
+// so it can be selected with REPLICATE_IMAGE_PROTOCOL=sixel.
+func DetectImageProtocol() ImageProtocol {
+	if !IsTTY() {
+		return ImageProtocolNone
+	}
+
+	if value, ok := os.LookupEnv("REPLICATE_IMAGE_PROTOCOL"); ok {
+		switch ImageProtocol(strings.ToLower(value)) {
+		case ImageProtocolKitty:
+			return ImageProtocolKitty
+		case ImageProtocolITerm:
+			return ImageProtocolITerm
+		case ImageProtocolSixel:
+			return ImageProtocolSixel
+		default:
+			return ImageProtocolNone
+		}
+	}
+
+	term := os.Getenv("TERM")
+	termProgram := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", termProgram == "ghostty":
+		return ImageProtocolKitty
+	case termProgram == "iTerm.app", termProgram == "WezTerm", os.Getenv("LC_TERMINAL") == "iTerm2":
+		return ImageProtocolITerm
+	case strings.Contains(term, "sixel"), term == "foot", term == "mlterm":
+		return ImageProtocolSixel
+	}
+
+	return ImageProtocolNone
+}
+
+// IsImageURL returns true if a URL or data URI looks like it refers to an image
+func IsImageURL(s string) bool {
+	if strings.HasPrefix(s, "data:") {
+		return strings.HasPrefix(s, "data:image/")
+	}
+
+	switch strings.ToLower(path.Ext(strings.SplitN(s, "?", 2)[0])) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return true
+	}
+
+	return false
+}
+
+// FetchImage returns the contents of an image URL or data URI
+func FetchImage(ctx context.Context, url string) ([]byte, error) {
+	if strings.HasPrefix(url, "data:") {
+		return DecodeDataURI(url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &downloadStatusError{StatusCode: resp.StatusCode}
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxInlineImageBytes))
+}
+
+// RenderImage writes an image to w using a terminal graphics protocol.
+// The image is scaled to fit the given number of terminal columns.
+func RenderImage(w io.Writer, data []byte, protocol ImageProtocol, columns int) error {
+	switch protocol {
+	case ImageProtocolITerm:
+		// iTerm decodes the image itself, so it's sent as-is
+		_, err := fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;width=%d;preserveAspectRatio=1:%s\a\n",
+			len(data), columns, base64.StdEncoding.EncodeToString(data))
+		return err
+	case ImageProtocolKitty:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to decode image: %w", err)
+		}
+		return writeKittyImage(w, img, columns)
+	case ImageProtocolSixel:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to decode image: %w", err)
+		}
+		return writeSixelImage(w, img)
+	default:
+		return fmt.Errorf("terminal doesn't support inline images")
+	}
+}
+
+// writeKittyImage transmits an image as PNG using the kitty graphics protocol
+func writeKittyImage(w io.Writer, img image.Image, columns int) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// Payloads must be sent in chunks of at most 4096 bytes
+	const chunkSize = 4096
+	for i := 0; i < len(encoded); i += chunkSize {
+		end := i + chunkSize
+		more := 1
+		if end >= len(encoded) {
+			end = len(encoded)
+			more = 0
+		}
+
+		var err error
+		if i == 0 {
+			_, err = fmt.Fprintf(w, "\x1b_Gf=100,a=T,c=%d,m=%d;%s\x1b\\", columns, more, encoded[i:end])
+		} else {
+			_, err = fmt.Fprintf(w, "\x1b_Gm=%d;%s\x1b\\", more, encoded[i:end])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// writeSixelImage encodes an image as sixels with a 256 color palette
+func writeSixelImage(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	if bounds.Dx() > maxSixelWidth {
+		height := bounds.Dy() * maxSixelWidth / bounds.Dx()
+		scaled := image.NewRGBA(image.Rect(0, 0, maxSixelWidth, height))
+		xdraw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+		img = scaled
+		bounds = scaled.Bounds()
+	}
+
+	paletted := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+
+	width, height := paletted.Rect.Dx(), paletted.Rect.Dy()
+
+	var buf bytes.Buffer
+	buf.WriteString("\x1bPq")
+	fmt.Fprintf(&buf, "\"1;1;%d;%d", width, height)
+
+	for i, c := range paletted.Palette {
+		r, g, b, _ := color.RGBAModel.Convert(c).RGBA()
+		fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	for band := 0; band < height; band += 6 {
+		// Collect the sixel bits for each color used in this band
+		rows := map[uint8][]byte{}
+		order := []uint8{}
+		for y := band; y < band+6 && y < height; y++ {
+			for x := 0; x < width; x++ {
+				idx := paletted.ColorIndexAt(x, y)
+				row, ok := rows[idx]
+				if !ok {
+					row = make([]byte, width)
+					rows[idx] = row
+					order = append(order, idx)
+				}
+				row[x] |= 1 << uint(y-band)
+			}
+		}
+
+		for i, idx := range order {
+			if i > 0 {
+				buf.WriteByte('$')
+			}
+			fmt.Fprintf(&buf, "#%d", idx)
+			writeSixelRow(&buf, rows[idx])
+		}
+		buf.WriteByte('-')
+	}
+
+	buf.WriteString("\x1b\\\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeSixelRow writes a row of sixels with run-length encoding
+func writeSixelRow(buf *bytes.Buffer, row []byte) {
+	for x := 0; x < len(row); {
+		run := 1
+		for x+run < len(row) && row[x+run] == row[x] {
+			run++
+		}
+
+		ch := byte('?' + row[x])
+		if run > 3 {
+			fmt.Fprintf(buf, "!%d%c", run, ch)
+		} else {
+			for i := 0; i < run; i++ {
+				buf.WriteByte(ch)
+			}
+		}
+		x += run
+	}
+}
@@ -0,0 +1,138 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/replicate/replicate-go"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadInputDocument reads the input document from a file at path or from JSON data,
+// or returns nil if neither is given
+func LoadInputDocument(ctx context.Context, r8 *replicate.Client, path string, data string, options InputOptions) (map[string]interface{}, error) {
+	switch {
+	case path != "":
+		return ReadInputFile(ctx, r8, path, options)
+	case data != "":
+		return ParseInputJSON(ctx, r8, data, options)
+	}
+
+	return nil, nil
+}
+
+// ReadInputFile reads an input document from a JSON or YAML file.
+// Relative @file references in the document are resolved from the file's directory.
+func ReadInputFile(ctx context.Context, r8 *replicate.Client, path string, options InputOptions) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	var doc map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	default:
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse input file %s: %w", path, err)
+	}
+
+	return ParseInputDocument(ctx, r8, doc, filepath.Dir(path), options)
+}
+
+// ParseInputJSON parses an input document from a JSON object.
+// Relative @file references are resolved from the working directory.
+func ParseInputJSON(ctx context.Context, r8 *replicate.Client, data string, options InputOptions) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse input JSON: %w", err)
+	}
+
+	return ParseInputDocument(ctx, r8, doc, ".", options)
+}
+
+// ParseInputDocument prepares a document of inputs to be merged with
+// the inputs from ParseInputsWithOptions and converted by CoerceInputs.
+//
+// Strings like "@path" that refer to existing files are uploaded,
+// or sent as text or data URIs, like @file inputs on the command line.
+// Each value is returned as json.RawMessage.
+func ParseInputDocument(ctx context.Context, r8 *replicate.Client, doc map[string]interface{}, dir string, options InputOptions) (map[string]interface{}, error) {
+	var resolve func(v interface{}) (interface{}, error)
+	resolve = func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case string:
+			if !strings.HasPrefix(v, "@") || v == "@-" {
+				return v, nil
+			}
+
+			path := strings.TrimSpace(v[1:])
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			// Strings that look like references but aren't, like "@username", are kept
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				return v, nil
+			}
+
+			return resolveInput(ctx, r8, "@"+path, "", options)
+		case []interface{}:
+			for i, item := range v {
+				resolved, err := resolve(item)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = resolved
+			}
+			return v, nil
+		case map[string]interface{}:
+			for k, item := range v {
+				resolved, err := resolve(item)
+				if err != nil {
+					return nil, err
+				}
+				v[k] = resolved
+			}
+			return v, nil
+		}
+
+		return v, nil
+	}
+
+	inputs := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		resolved, err := resolve(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input %s: %w", k, err)
+		}
+
+		data, err := json.Marshal(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal input %s: %w", k, err)
+		}
+
+		inputs[k] = json.RawMessage(data)
+	}
+
+	return inputs, nil
+}
+
+// MergeInputs returns the inputs in base, replaced by any inputs in overrides
+func MergeInputs(base map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
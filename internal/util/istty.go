@@ -20,3 +20,9 @@ func IsTTY() bool {
 
 	return isTTY
 }
+
+// SetTTY overrides whether IsTTY reports a terminal, for tests.
+func SetTTY(tty bool) {
+	checkTTY.Do(func() {})
+	isTTY = tty
+}
@@ -3,77 +3,388 @@ package util
 import (
 	"bufio"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/PaesslerAG/jsonpath"
 	"github.com/replicate/replicate-go"
 )
 
+// UploadMetadata is attached to files uploaded for @file inputs,
+// so they can be told apart from files uploaded with "file upload"
+var UploadMetadata = map[string]string{"uploaded_by": "replicate-cli"}
+
+// maxInlineTextSize is the largest text file that's sent as its contents
+// when InputOptions.InlineText is set
+const maxInlineTextSize = 64 << 10
+
+// InputOptions configures how inputs are parsed
+type InputOptions struct {
+	// Separator is between the key and value of each input, like "="
+	Separator string
+
+	// DataURIs reads @file inputs into data URIs instead of uploading them,
+	// for servers that can't fetch uploaded files, like a local Cog server
+	DataURIs bool
+
+	// InlineText sends small text files given as @file inputs
+	// as their contents instead of uploading them
+	InlineText bool
+
+	// OnUpload is called with each file uploaded for an @file input
+	OnUpload func(file *replicate.File)
+}
+
+// ParseInputs parses key=value inputs, uploading @file inputs with r8
 func ParseInputs(ctx context.Context, r8 *replicate.Client, args []string, stdin string, sep string) (map[string]string, error) {
+	inputs, err := ParseInputsWithOptions(ctx, r8, args, stdin, InputOptions{Separator: sep})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(inputs))
+	for k, v := range inputs {
+		switch v := v.(type) {
+		case string:
+			values[k] = v
+		case json.RawMessage:
+			values[k] = string(v)
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal input %s: %w", k, err)
+			}
+			values[k] = string(b)
+		}
+	}
+
+	return values, nil
+}
+
+// ParseInputsWithOptions parses inputs as specified by the options.
+//
+// Each argument is a key and value separated by the separator, like key=value.
+// Values can be:
+//   - text, which is converted to the input's type by CoerceInputs
+//   - @path to a file, which is uploaded, or sent as text or a data URI
+//   - @- to read the value from stdin
+//   - env:NAME to read the value from an environment variable
+//   - {{.path}} to extract a value from JSON piped to stdin,
+//     like a prediction from "replicate run --json"
+//   - pred:<id>#<path> to extract a value from another prediction,
+//     which defaults to its output
+//
+// URLs and data URIs are sent as they are.
+// An argument like key:=value sets the input to raw JSON.
+// Repeating a key makes a list of its values, for array inputs.
+//
+// Values are strings, json.RawMessage for raw JSON,
+// or []interface{} of those for repeated keys.
+func ParseInputsWithOptions(ctx context.Context, r8 *replicate.Client, args []string, stdin string, options InputOptions) (map[string]interface{}, error) {
+	sep := options.Separator
 	re := regexp.MustCompile(`{{(.*?)}}`)
 
-	inputs := make(map[string]string)
+	var stdinJSON interface{}
+	stdinParsed := false
+
+	inputs := make(map[string]interface{})
 	for _, e := range args {
 		k, v, found := strings.Cut(e, sep)
 		if !found {
 			return nil, fmt.Errorf("invalid input: %s", e)
 		}
 
-		var stdinJSON map[string]interface{}
-		if stdin != "" {
-			err := json.Unmarshal([]byte(stdin), &stdinJSON)
+		// Set raw JSON
+		if key, ok := strings.CutSuffix(k, ":"); ok {
+			if !json.Valid([]byte(v)) {
+				return nil, fmt.Errorf("invalid JSON for input %s: %s", key, v)
+			}
+
+			addInput(inputs, key, json.RawMessage(v))
+			continue
+		}
+
+		// A backslash before a source sends the rest of the value as is
+		if literal, ok := cutEscape(v); ok {
+			addInput(inputs, k, literal)
+			continue
+		}
+
+		// Extract data from another prediction
+		if ref, ok := strings.CutPrefix(v, "pred:"); ok {
+			value, err := resolvePredictionRef(ctx, r8, ref)
 			if err != nil {
-				return nil, fmt.Errorf("failed to unmarshal stdin: %w", err)
+				return nil, fmt.Errorf("failed to read input %s: %w", k, err)
 			}
+
+			addInput(inputs, k, value)
+			continue
 		}
 
 		// Extract data from JSON
 		matches := re.FindAllStringSubmatch(v, -1)
+		if len(matches) > 0 && !stdinParsed {
+			if stdin != "" {
+				err := json.Unmarshal([]byte(stdin), &stdinJSON)
+				if err != nil {
+					return nil, fmt.Errorf("failed to unmarshal stdin: %w", err)
+				}
+
+				// Output piped from "prediction output" isn't wrapped in a prediction
+				if _, ok := stdinJSON.(map[string]interface{}); !ok {
+					stdinJSON = map[string]interface{}{"output": stdinJSON}
+				}
+			}
+			stdinParsed = true
+		}
+
+		// A value that's only a path, like {{.output}}, keeps the type of the extracted value
+		if len(matches) == 1 && matches[0][0] == v {
+			value, err := extractJSON(matches[0][1], stdinJSON)
+			if err != nil {
+				return nil, err
+			}
+
+			addInput(inputs, k, value)
+			continue
+		}
+
 		for _, match := range matches {
 			if len(match) < 2 {
 				continue
 			}
 
-			path := strings.TrimSpace(match[1])
-			if !strings.HasPrefix(path, "$") {
-				path = "$" + path
-			}
-
-			value, err := jsonpath.Get(path, stdinJSON)
+			value, err := extractJSON(match[1], stdinJSON)
 			if err != nil {
-				return nil, fmt.Errorf("failed to extract data from JSON using path '%s': %w", path, err)
+				return nil, err
 			}
 
 			// Replace the segment with the extracted value
+			if text, ok := value.(json.RawMessage); ok {
+				value = string(text)
+			}
 			v = strings.Replace(v, match[0], fmt.Sprintf("%v", value), 1)
 		}
 
-		// Read from file
-		if strings.HasPrefix(v, "@") {
-			path := strings.TrimSpace(v[1:])
+		value, err := resolveInput(ctx, r8, v, stdin, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input %s: %w", k, err)
+		}
 
-			file, err := r8.CreateFileFromPath(ctx, path, nil)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create file from path: %w", err)
-			}
+		addInput(inputs, k, value)
+	}
+
+	return inputs, nil
+}
+
+// extractJSON returns the value at a JSON path like ".output[0]", "output[0]" or "$.output[0]".
+// Strings are returned as they are, and other values as json.RawMessage.
+func extractJSON(path string, data interface{}) (interface{}, error) {
+	path = strings.TrimSpace(path)
+	switch {
+	case strings.HasPrefix(path, "$"):
+	case strings.HasPrefix(path, "."), strings.HasPrefix(path, "["):
+		path = "$" + path
+	default:
+		path = "$." + path
+	}
 
-			downloadURL := file.URLs["get"]
-			if downloadURL == "" {
-				return nil, fmt.Errorf("failed to get download URL for file")
+	value, err := jsonpath.Get(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract data from JSON using path '%s': %w", path, err)
+	}
+
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data from JSON using path '%s': %w", path, err)
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// resolvePredictionRef returns the value at a path in a prediction,
+// for a reference like "<id>#$.output[0]", waiting for the prediction to finish.
+// The path defaults to the prediction's output.
+func resolvePredictionRef(ctx context.Context, r8 *replicate.Client, ref string) (interface{}, error) {
+	id, path, found := strings.Cut(ref, "#")
+	if !found || path == "" {
+		path = "$.output"
+	}
+	if id == "" {
+		return nil, fmt.Errorf("invalid prediction reference: pred:%s", ref)
+	}
+
+	id, err := ParsePredictionID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prediction reference: pred:%s: %w", ref, err)
+	}
+
+	// Predictions run with a local Cog server don't have an API client
+	if r8 == nil {
+		return nil, InvalidInputf("pred: references need the Replicate API, so can't be used with a local Cog server: pred:%s", ref)
+	}
+
+	prediction, err := r8.GetPrediction(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prediction %s: %w", id, err)
+	}
+
+	if !prediction.Status.Terminated() {
+		if err := r8.Wait(ctx, prediction); err != nil {
+			return nil, fmt.Errorf("failed to wait for prediction %s: %w", id, err)
+		}
+	}
+
+	if prediction.Status != replicate.Succeeded {
+		return nil, fmt.Errorf("prediction %s didn't succeed: %s", id, prediction.Status)
+	}
+
+	// Round trip through JSON so the path matches the API's field names
+	b, err := json.Marshal(prediction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal prediction %s: %w", id, err)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prediction %s: %w", id, err)
+	}
+
+	return extractJSON(path, data)
+}
+
+// resolveInput reads a value from the source it refers to
+func resolveInput(ctx context.Context, r8 *replicate.Client, v string, stdin string, options InputOptions) (string, error) {
+	switch {
+	case v == "@-":
+		if stdin == "" {
+			return "", fmt.Errorf("nothing was piped to stdin")
+		}
+		return stdin, nil
+	case strings.HasPrefix(v, "@"):
+		path := strings.TrimSpace(v[1:])
+
+		if options.InlineText {
+			if text, ok, err := readTextFile(path); err != nil {
+				return "", err
+			} else if ok {
+				return text, nil
 			}
+		}
+
+		if options.DataURIs {
+			return FileToDataURI(path)
+		}
 
-			v = downloadURL
+		file, err := r8.CreateFileFromPath(ctx, path, &replicate.CreateFileOptions{Metadata: UploadMetadata})
+		if err != nil {
+			return "", fmt.Errorf("failed to create file from path: %w", err)
 		}
 
-		inputs[k] = v
+		if options.OnUpload != nil {
+			options.OnUpload(file)
+		}
+
+		downloadURL := file.URLs["get"]
+		if downloadURL == "" {
+			return "", fmt.Errorf("failed to get download URL for file")
+		}
+
+		return downloadURL, nil
+	case strings.HasPrefix(v, "env:"):
+		name := v[len("env:"):]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s isn't set", name)
+		}
+		return value, nil
 	}
 
-	return inputs, nil
+	return v, nil
+}
+
+// cutEscape returns a value like "\env:HOME" without its backslash,
+// if the rest of it would otherwise be read from a file, stdin, the environment or another prediction
+func cutEscape(v string) (string, bool) {
+	rest, ok := strings.CutPrefix(v, "\\")
+	if !ok {
+		return v, false
+	}
+
+	for _, prefix := range []string{"@", "env:", "pred:"} {
+		if strings.HasPrefix(rest, prefix) {
+			return rest, true
+		}
+	}
+
+	return v, false
+}
+
+// addInput sets an input, making a list of values if the key is repeated
+func addInput(inputs map[string]interface{}, key string, value interface{}) {
+	existing, ok := inputs[key]
+	if !ok {
+		inputs[key] = value
+		return
+	}
+
+	if list, ok := existing.([]interface{}); ok {
+		inputs[key] = append(list, value)
+	} else {
+		inputs[key] = []interface{}{existing, value}
+	}
+}
+
+// readTextFile returns the contents of a file if it's small and looks like text
+func readTextFile(path string) (string, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if info.Size() > maxInlineTextSize {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if !utf8.Valid(data) || !strings.HasPrefix(http.DetectContentType(data), "text/") {
+		return "", false, nil
+	}
+
+	return string(data), true, nil
+}
+
+// FileToDataURI reads a file into a base64 data URI
+func FileToDataURI(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	mediaType := mime.TypeByExtension(filepath.Ext(path))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+
+	return fmt.Sprintf("data:%s;base64,%s", mediaType, base64.StdEncoding.EncodeToString(data)), nil
 }
 
 func GetPipedArgs() (string, error) {
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/replicate/cli/internal/history"
+)
+
+// ParsePredictionID parses the prediction id from a url or the number of a history entry,
+// or returns the prediction id if it's neither
+func ParsePredictionID(value string) (string, error) {
+	// Case 0: The number of a prediction in the CLI's history
+	if id, ok, err := history.Resolve(value, history.KindPrediction); ok {
+		return id, err
+	}
+
+	// Case 1: A prediction ID
+	if !strings.Contains(value, "/") {
+		return value, nil
+	}
+
+	// Case 2: A URL in the form https://replicate.com/p/{id}
+	if strings.HasPrefix(value, "replicate.com/p/") || strings.HasPrefix(value, "https://replicate.com/p/") {
+		splitURL := strings.Split(value, "/")
+		if len(splitURL) == 0 {
+			return "", fmt.Errorf("invalid URL format")
+		}
+		return splitURL[len(splitURL)-1], nil
+	}
+
+	// Case 3: A URL in the form https://api.replicate.com/v1/predictions/{id}
+	if strings.HasPrefix(value, "api.replicate.com/v1/predictions/") || strings.HasPrefix(value, "https://api.replicate.com/v1/predictions/") {
+		splitURL := strings.Split(value, "/")
+		if len(splitURL) == 0 {
+			return "", fmt.Errorf("invalid URL format")
+		}
+		return splitURL[len(splitURL)-1], nil
+	}
+
+	// Case 4: A URL in the form "https://replicate.com/*?prediction={id}"
+	if strings.Contains(value, "replicate.com") || strings.Contains(value, "https://replicate.com") {
+		parsedURL, err := url.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse URL: %w", err)
+		}
+		queryParams, err := url.ParseQuery(parsedURL.RawQuery)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse query parameters: %w", err)
+		}
+		predictionID := queryParams.Get("prediction")
+		if predictionID == "" {
+			return "", fmt.Errorf("no prediction ID found in URL")
+		}
+		return predictionID, nil
+	}
+
+	// If none of the above cases match, return an error
+	return "", fmt.Errorf("invalid prediction ID or URL format")
+}
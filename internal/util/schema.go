@@ -2,9 +2,12 @@ package util
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/replicate/replicate-go"
@@ -12,17 +15,11 @@ import (
 
 // GetSchemas returns the input and output schemas for a model version
 func GetSchemas(version replicate.ModelVersion) (input *openapi3.Schema, output *openapi3.Schema, err error) {
-	bytes, err := json.Marshal(version.OpenAPISchema)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to serialize schema: %w", err)
-	}
-
-	spec, err := openapi3.NewLoader().LoadFromData(bytes)
+	schemas, err := loadSchemas(version)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse schema: %w", err)
+		return nil, nil, err
 	}
 
-	schemas := spec.Components.Schemas
 	inputSchemaRef := schemas["Input"]
 	outputSchemaRef := schemas["Output"]
 
@@ -37,6 +34,35 @@ func GetSchemas(version replicate.ModelVersion) (input *openapi3.Schema, output
 	return input, output, nil
 }
 
+// GetTrainingInputSchema returns the schema for training inputs of a model version,
+// or nil if the version can't be trained
+func GetTrainingInputSchema(version replicate.ModelVersion) (*openapi3.Schema, error) {
+	schemas, err := loadSchemas(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref := schemas["TrainingInput"]; ref != nil {
+		return ref.Value, nil
+	}
+
+	return nil, nil
+}
+
+func loadSchemas(version replicate.ModelVersion) (openapi3.Schemas, error) {
+	bytes, err := json.Marshal(version.OpenAPISchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize schema: %w", err)
+	}
+
+	spec, err := openapi3.NewLoader().LoadFromData(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	return spec.Components.Schemas, nil
+}
+
 // SortedKeys returns the keys of the properties in the order they should be displayed
 func SortedKeys(properties openapi3.Schemas) []string {
 	keys := make([]string, 0, len(properties))
@@ -88,6 +114,134 @@ func CoerceTypes(inputs map[string]string, schema *openapi3.Schema) (map[string]
 	return coerced, nil
 }
 
+// ValidateInputs checks inputs against the input schema,
+// returning an error that describes every invalid input
+func ValidateInputs(inputs map[string]interface{}, schema *openapi3.Schema) error {
+	if schema == nil {
+		return nil
+	}
+
+	err := schema.VisitJSON(inputs, openapi3.MultiErrors(), openapi3.VisitAsRequest())
+	if err == nil {
+		return nil
+	}
+
+	var errs openapi3.MultiError
+	if !errors.As(err, &errs) {
+		errs = openapi3.MultiError{err}
+	}
+
+	messages := []string{}
+	for _, err := range errs {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(err, &schemaErr) {
+			if path := schemaErr.JSONPointer(); len(path) > 0 {
+				messages = append(messages, fmt.Sprintf("%s: %s", strings.Join(path, "."), schemaErr.Reason))
+			} else {
+				messages = append(messages, schemaErr.Reason)
+			}
+		} else {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// CoerceInputs converts inputs from ParseInputsWithOptions to the types specified in the schema.
+// Text is converted like CoerceTypes, raw JSON is decoded and converted where unambiguous,
+// and lists of values from repeated keys are converted item by item.
+func CoerceInputs(inputs map[string]interface{}, schema *openapi3.Schema) (map[string]interface{}, error) {
+	coerced := map[string]interface{}{}
+	for k, v := range inputs {
+		var propSchema *openapi3.Schema
+		if schema != nil {
+			prop, ok := schema.Properties[k]
+			if ok {
+				propSchema = prop.Value
+			}
+		}
+
+		coercedValue, err := coerceInput(v, propSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to coerce property %s: %w", k, err)
+		}
+		coerced[k] = coercedValue
+	}
+
+	return coerced, nil
+}
+
+// coerceInput converts a parsed input to the type specified in the schema
+func coerceInput(input interface{}, schema *openapi3.Schema) (interface{}, error) {
+	switch v := input.(type) {
+	case string:
+		coerced, err := coerceType(v, schema)
+		if err != nil || coerced == nil {
+			return nil, fmt.Errorf("failed to coerce %s: %w", v, err)
+		}
+		return coerced, nil
+	case json.RawMessage:
+		var decoded interface{}
+		if err := json.Unmarshal(v, &decoded); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+		}
+		return coerceValue(decoded, schema)
+	case []interface{}:
+		if schema != nil && !schema.Type.Is("array") {
+			return nil, fmt.Errorf("got %d values, but the input isn't an array", len(v))
+		}
+
+		var items *openapi3.Schema
+		if schema != nil && schema.Items != nil {
+			items = schema.Items.Value
+		}
+
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			coerced, err := coerceInput(item, items)
+			if err != nil {
+				return nil, fmt.Errorf("failed to coerce item %d: %w", i, err)
+			}
+			values[i] = coerced
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("unsupported input type %T", input)
+}
+
+// coerceValue converts a decoded JSON value to the type specified in the schema
+// where that's unambiguous, like "20" or 20.0 for an integer
+func coerceValue(value interface{}, schema *openapi3.Schema) (interface{}, error) {
+	if schema == nil || schema.Type == nil {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		if schema.Type.Is("integer") || schema.Type.Is("number") || schema.Type.Is("boolean") {
+			return coerceType(v, schema)
+		}
+	case float64:
+		if schema.Type.Is("integer") && v == math.Trunc(v) {
+			return int(v), nil
+		}
+	case []interface{}:
+		if schema.Type.Is("array") && schema.Items != nil {
+			for i, item := range v {
+				coerced, err := coerceValue(item, schema.Items.Value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to coerce item %d: %w", i, err)
+				}
+				v[i] = coerced
+			}
+		}
+	}
+
+	return value, nil
+}
+
 // coerceType converts a string to the type specified in the schema
 func coerceType(input string, schema *openapi3.Schema) (interface{}, error) {
 	if schema == nil {
@@ -113,18 +267,32 @@ func coerceType(input string, schema *openapi3.Schema) (interface{}, error) {
 	}
 	if schema.Type.Is("array") {
 		var value []interface{}
-		err := json.Unmarshal([]byte(input), &value)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal array: %w", err)
+		if strings.HasPrefix(strings.TrimSpace(input), "[") {
+			if err := json.Unmarshal([]byte(input), &value); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal array: %w", err)
+			}
+		} else {
+			// A single value is an array of one item
+			value = []interface{}{input}
+		}
+
+		var items *openapi3.Schema
+		if schema.Items != nil {
+			items = schema.Items.Value
 		}
 
 		for i, v := range value {
-			encoded, err := json.Marshal(v)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal item %d: %w", i, err)
+			// Strings are coerced as they are, so they don't keep their quotes
+			item, ok := v.(string)
+			if !ok {
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal item %d: %w", i, err)
+				}
+				item = string(encoded)
 			}
 
-			coerced, err := coerceType(string(encoded), schema.Items.Value)
+			coerced, err := coerceType(item, items)
 			if err != nil || coerced == nil {
 				return nil, fmt.Errorf("failed to coerce item %d: %w", i, err)
 			}
@@ -134,6 +302,15 @@ func coerceType(input string, schema *openapi3.Schema) (interface{}, error) {
 
 		return value, nil
 	}
+	if schema.Type.Is("object") {
+		var value map[string]interface{}
+		err := json.Unmarshal([]byte(input), &value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal object: %w", err)
+		}
+
+		return value, nil
+	}
 
 	// If the property has a default value, attempt to convert to that type
 	switch schema.Default.(type) {
@@ -1,27 +1,40 @@
 package util_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/replicate/replicate-go"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/replicate/cli/internal/history"
 	"github.com/replicate/cli/internal/util"
 )
 
 func TestParseInputs(t *testing.T) {
 	ctx := context.Background()
 
+	var metadata string
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "POST" && r.URL.Path == "/files" {
+			metadata = r.FormValue("metadata")
+
 			file := &replicate.File{
 				URLs: map[string]string{
 					"get": "https://api.replicate.com/v1/files/123",
@@ -90,6 +103,17 @@ func TestParseInputs(t *testing.T) {
 		"array_of_integers": "[1,2,3]",
 		"file":              "https://api.replicate.com/v1/files/123",
 	}, inputs)
+	assert.JSONEq(t, `{"uploaded_by":"replicate-cli"}`, metadata)
+
+	uploads := []*replicate.File{}
+	_, err = util.ParseInputsWithOptions(ctx, r8, []string{"file=@" + tmpFilePath}, "", util.InputOptions{
+		Separator: "=",
+		OnUpload: func(file *replicate.File) {
+			uploads = append(uploads, file)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, uploads, 1)
 }
 
 func TestCoerceTypesWithSchema(t *testing.T) {
@@ -350,3 +374,615 @@ func TestCoerceTypesWithoutSchema(t *testing.T) {
 		}
 	})
 }
+
+func TestCollectOutputFiles(t *testing.T) {
+	t.Run("single URL", func(t *testing.T) {
+		files, onlyFiles := util.CollectOutputFiles("https://example.com/out.png")
+		assert.True(t, onlyFiles)
+		assert.Len(t, files, 1)
+		assert.Equal(t, "out.png", files[0].Basename)
+		assert.Equal(t, "out", files[0].Name)
+		assert.Equal(t, ".png", files[0].Ext)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		output := map[string]interface{}{
+			"text": "hello",
+			"images": []interface{}{
+				"https://example.com/a/out.png",
+				"data:text/plain;base64,aGVsbG8=",
+			},
+		}
+
+		files, onlyFiles := util.CollectOutputFiles(output)
+		assert.False(t, onlyFiles)
+		assert.Len(t, files, 2)
+		assert.Equal(t, "images.0", files[0].Key)
+		assert.Equal(t, "images.1", files[1].Key)
+		assert.Equal(t, 1, files[1].Index)
+	})
+
+	t.Run("no files", func(t *testing.T) {
+		files, onlyFiles := util.CollectOutputFiles([]interface{}{"hello", 1.0})
+		assert.False(t, onlyFiles)
+		assert.Empty(t, files)
+	})
+}
+
+func TestDownloader(t *testing.T) {
+	ctx := context.Background()
+
+	content := []byte("0123456789")
+	failures := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing/out.png":
+			w.WriteHeader(http.StatusNotFound)
+		case "/flaky/out.png":
+			if failures == 0 {
+				failures++
+				// Send half the file and drop the connection
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				w.WriteHeader(http.StatusOK)
+				w.Write(content[:5])
+				return
+			}
+			assert.Equal(t, "bytes=5-", r.Header.Get("Range"))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 5-9/%d", len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[5:])
+		case "/complete/out.png":
+			if r.Header.Get("Range") != "" {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(content)))
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.Write(content)
+		default:
+			w.Write(content)
+		}
+	}))
+	defer mockServer.Close()
+
+	prediction := replicate.Prediction{
+		ID:     "abc123",
+		Status: replicate.Succeeded,
+	}
+
+	t.Run("collisions and templates", func(t *testing.T) {
+		dir := t.TempDir()
+		prediction.Output = []interface{}{
+			mockServer.URL + "/a/out.png",
+			mockServer.URL + "/b/out.png",
+			"data:text/plain,hello",
+		}
+
+		d := &util.Downloader{Template: "{{.Index}}-{{.Basename}}"}
+		files, err := d.Download(ctx, prediction, dir)
+		assert.NoError(t, err)
+		assert.Len(t, files, 3)
+		assert.Equal(t, filepath.Join(dir, "0-out.png"), files[0].Path)
+		assert.Equal(t, filepath.Join(dir, "1-out.png"), files[1].Path)
+		assert.Equal(t, filepath.Join(dir, "2-output.txt"), files[2].Path)
+
+		d = &util.Downloader{}
+		files, err = d.Download(ctx, prediction, dir)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "out.png"), files[0].Path)
+		assert.Equal(t, filepath.Join(dir, "out-1.png"), files[1].Path)
+
+		data, err := os.ReadFile(files[2].Path)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		assert.NoFileExists(t, filepath.Join(dir, "output.json"))
+	})
+
+	t.Run("checks status", func(t *testing.T) {
+		prediction.Output = mockServer.URL + "/missing/out.png"
+		_, err := (&util.Downloader{}).Download(ctx, prediction, t.TempDir())
+		assert.ErrorContains(t, err, "404")
+	})
+
+	t.Run("resumes", func(t *testing.T) {
+		prediction.Output = mockServer.URL + "/flaky/out.png"
+		files, err := (&util.Downloader{}).Download(ctx, prediction, t.TempDir())
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+		assert.Equal(t, int64(len(content)), files[0].Size)
+		assert.Equal(t, "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882", files[0].SHA256)
+	})
+
+	t.Run("restarts stale partial downloads", func(t *testing.T) {
+		prediction.Output = mockServer.URL + "/complete/out.png"
+		for _, partial := range []string{"0123456789", "abcdefghijkl"} {
+			dir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "out.png.part"), []byte(partial), 0o644))
+
+			files, err := (&util.Downloader{}).Download(ctx, prediction, dir)
+			assert.NoError(t, err)
+			data, err := os.ReadFile(files[0].Path)
+			assert.NoError(t, err)
+			assert.Equal(t, content, data)
+		}
+	})
+
+	t.Run("file names", func(t *testing.T) {
+		prediction.Output = mockServer.URL + "/a/out.png"
+
+		files, err := (&util.Downloader{Template: "..{{.Basename}}"}).Download(ctx, prediction, t.TempDir())
+		assert.NoError(t, err)
+		assert.Equal(t, "..out.png", filepath.Base(files[0].Path))
+
+		for _, template := range []string{"..", "../{{.Basename}}", "/{{.Basename}}"} {
+			_, err := (&util.Downloader{Template: template}).Download(ctx, prediction, t.TempDir())
+			assert.ErrorContains(t, err, "invalid file name", template)
+		}
+	})
+
+	t.Run("writes other output", func(t *testing.T) {
+		dir := t.TempDir()
+		prediction.Output = "hello"
+		_, err := (&util.Downloader{}).Download(ctx, prediction, dir)
+		assert.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, "output.json"))
+	})
+}
+
+func TestParsePredictionID(t *testing.T) {
+	for _, value := range []string{
+		"jpgp263bdekvxileu2ppsy46v4",
+		"https://replicate.com/p/jpgp263bdekvxileu2ppsy46v4",
+		"replicate.com/p/jpgp263bdekvxileu2ppsy46v4",
+		"https://api.replicate.com/v1/predictions/jpgp263bdekvxileu2ppsy46v4",
+		"https://replicate.com/stability-ai/sdxl?prediction=jpgp263bdekvxileu2ppsy46v4",
+	} {
+		id, err := util.ParsePredictionID(value)
+		assert.NoError(t, err)
+		assert.Equal(t, "jpgp263bdekvxileu2ppsy46v4", id)
+	}
+
+	_, err := util.ParsePredictionID("https://example.com/p/123")
+	assert.Error(t, err)
+
+	// Numbers are entries in history
+	history.Path = filepath.Join(t.TempDir(), "history.jsonl")
+	assert.NoError(t, history.RecordPrediction(&replicate.Prediction{ID: "jpgp263bdekvxileu2ppsy46v4"}))
+	id, err := util.ParsePredictionID("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "jpgp263bdekvxileu2ppsy46v4", id)
+	_, err = util.ParsePredictionID("2")
+	assert.Error(t, err)
+}
+
+func TestRenderImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for x := 0; x < 8; x++ {
+		img.Set(x, x, color.RGBA{255, 0, 0, 255})
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	assert.NoError(t, err)
+	data := buf.Bytes()
+
+	t.Run("sixel", func(t *testing.T) {
+		var out bytes.Buffer
+		err := util.RenderImage(&out, data, util.ImageProtocolSixel, 10)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out.String(), "\x1bPq\"1;1;8;8"))
+		assert.Contains(t, out.String(), "\x1b\\")
+	})
+
+	t.Run("kitty", func(t *testing.T) {
+		var out bytes.Buffer
+		err := util.RenderImage(&out, data, util.ImageProtocolKitty, 10)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out.String(), "\x1b_Gf=100,a=T,c=10,m=0;"))
+	})
+
+	t.Run("iterm", func(t *testing.T) {
+		var out bytes.Buffer
+		err := util.RenderImage(&out, data, util.ImageProtocolITerm, 10)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out.String(), "\x1b]1337;File=inline=1;"))
+	})
+}
+
+func TestDiff(t *testing.T) {
+	lines := util.Diff("a\nb\nc", "a\nc\nd")
+	assert.Equal(t, []util.DiffLine{
+		{Op: util.DiffEqual, Text: "a"},
+		{Op: util.DiffDelete, Text: "b"},
+		{Op: util.DiffEqual, Text: "c"},
+		{Op: util.DiffInsert, Text: "d"},
+	}, lines)
+
+	assert.Equal(t, "--- a\n+++ b\n a\n-b\n c\n+d\n", util.FormatDiff("a", "b", lines))
+}
+
+func TestParseWebhook(t *testing.T) {
+	webhook, err := util.ParseWebhook("", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, webhook)
+
+	webhook, err = util.ParseWebhook("https://example.com/hook", []string{"start", " completed"})
+	assert.NoError(t, err)
+	assert.Equal(t, &replicate.Webhook{
+		URL:    "https://example.com/hook",
+		Events: []replicate.WebhookEventType{replicate.WebhookEventStart, replicate.WebhookEventCompleted},
+	}, webhook)
+
+	_, err = util.ParseWebhook("https://example.com/hook", []string{"finished"})
+	assert.Error(t, err)
+
+	_, err = util.ParseWebhook("example.com/hook", nil)
+	assert.Error(t, err)
+
+	_, err = util.ParseWebhook("", []string{"start"})
+	assert.Error(t, err)
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	secret := "whsec_" + base64.StdEncoding.EncodeToString([]byte("test-secret-key"))
+	body := []byte(`{"id":"abc","status":"succeeded"}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	signature, err := util.SignWebhook("msg_1", timestamp, body, secret)
+	assert.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("webhook-id", "msg_1")
+	header.Set("webhook-timestamp", timestamp)
+	header.Set("webhook-signature", signature)
+
+	assert.NoError(t, util.VerifyWebhook(header, body, secret, time.Minute))
+	assert.Error(t, util.VerifyWebhook(header, []byte(`{"id":"abc","status":"failed"}`), secret, 0))
+
+	otherSecret := "whsec_" + base64.StdEncoding.EncodeToString([]byte("other-secret-key"))
+	assert.Error(t, util.VerifyWebhook(header, body, otherSecret, 0))
+
+	// Old webhooks are only rejected when there's a tolerance
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	signature, err = util.SignWebhook("msg_1", old, body, secret)
+	assert.NoError(t, err)
+	header.Set("webhook-timestamp", old)
+	header.Set("webhook-signature", signature)
+	assert.Error(t, util.VerifyWebhook(header, body, secret, time.Minute))
+	assert.NoError(t, util.VerifyWebhook(header, body, secret, 0))
+}
+
+func TestParseInputsWithDataURIs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("hello"), 0o644))
+
+	inputs, err := util.ParseInputsWithOptions(context.Background(), nil, []string{"text=@" + path, "n=1"}, "", util.InputOptions{
+		Separator: "=",
+		DataURIs:  true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"text": "data:text/plain;base64,aGVsbG8=",
+		"n":    "1",
+	}, inputs)
+}
+
+func TestValidateInputs(t *testing.T) {
+	maximum := 50.0
+	schema := &openapi3.Schema{
+		Type:     &openapi3.Types{"object"},
+		Required: []string{"prompt"},
+		Properties: openapi3.Schemas{
+			"prompt": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+			"steps":  &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}, Max: &maximum}},
+		},
+	}
+
+	assert.NoError(t, util.ValidateInputs(map[string]interface{}{"prompt": "a corgi", "steps": 10}, schema))
+	assert.NoError(t, util.ValidateInputs(map[string]interface{}{"prompt": "a corgi"}, nil))
+
+	err := util.ValidateInputs(map[string]interface{}{"steps": 99}, schema)
+	assert.ErrorContains(t, err, "steps: number must be at most 50")
+	assert.ErrorContains(t, err, `property "prompt" is missing`)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", util.FormatBytes(0))
+	assert.Equal(t, "999 B", util.FormatBytes(999))
+	assert.Equal(t, "1.5 kB", util.FormatBytes(1500))
+	assert.Equal(t, "2.0 MB", util.FormatBytes(2_000_000))
+}
+
+func TestParseInputSources(t *testing.T) {
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "prompt.txt")
+	assert.NoError(t, os.WriteFile(textPath, []byte("a corgi"), 0o644))
+	binaryPath := filepath.Join(dir, "image.png")
+	assert.NoError(t, os.WriteFile(binaryPath, []byte("\x89PNG\r\n\x1a\n\x00"), 0o644))
+
+	t.Setenv("TEST_NEGATIVE_PROMPT", "blurry")
+
+	args := []string{
+		"prompt=@" + textPath,
+		"image=@" + binaryPath,
+		"negative_prompt=env:TEST_NEGATIVE_PROMPT",
+		"caption=@-",
+		"url=https://example.com/a.png?size=1",
+		"mask=data:image/png;base64,AAAA",
+		"options:={\"seed\": 42}",
+		"tags=a",
+		"tags=b",
+		`note=\env:TEST_NEGATIVE_PROMPT`,
+		`ref=\pred:abc`,
+		`handle=\@corgi`,
+		`path=\tmp`,
+	}
+
+	inputs, err := util.ParseInputsWithOptions(context.Background(), nil, args, "from stdin", util.InputOptions{
+		Separator:  "=",
+		DataURIs:   true,
+		InlineText: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"prompt":          "a corgi",
+		"image":           "data:image/png;base64,iVBORw0KGgoA",
+		"negative_prompt": "blurry",
+		"caption":         "from stdin",
+		"url":             "https://example.com/a.png?size=1",
+		"mask":            "data:image/png;base64,AAAA",
+		"options":         json.RawMessage(`{"seed": 42}`),
+		"tags":            []interface{}{"a", "b"},
+		"note":            "env:TEST_NEGATIVE_PROMPT",
+		"ref":             "pred:abc",
+		"handle":          "@corgi",
+		"path":            `\tmp`,
+	}, inputs)
+
+	_, err = util.ParseInputsWithOptions(context.Background(), nil, []string{"prompt=env:TEST_UNSET_VARIABLE"}, "", util.InputOptions{Separator: "="})
+	assert.Error(t, err)
+
+	_, err = util.ParseInputsWithOptions(context.Background(), nil, []string{"prompt=@-"}, "", util.InputOptions{Separator: "="})
+	assert.Error(t, err)
+
+	_, err = util.ParseInputsWithOptions(context.Background(), nil, []string{"options:={"}, "", util.InputOptions{Separator: "="})
+	assert.Error(t, err)
+}
+
+func TestCoerceInputs(t *testing.T) {
+	schema := &openapi3.Schema{
+		Type: &openapi3.Types{"object"},
+		Properties: openapi3.Schemas{
+			"tags": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:  &openapi3.Types{"array"},
+				Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+			}},
+			"sizes": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:  &openapi3.Types{"array"},
+				Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}}},
+			}},
+			"images": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:  &openapi3.Types{"array"},
+				Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+			}},
+			"options": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"object"}}},
+			"prompt":  &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+			"text":    &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+		},
+	}
+
+	coerced, err := util.CoerceInputs(map[string]interface{}{
+		"tags":    `["a","b"]`,
+		"sizes":   []interface{}{"1", "2"},
+		"images":  "https://example.com/a.png",
+		"options": `{"seed":1}`,
+		"prompt":  json.RawMessage(`"a \"quoted\" corgi"`),
+		"text":    "plain",
+		"other":   json.RawMessage(`[1, 2]`),
+	}, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"tags":    []interface{}{"a", "b"},
+		"sizes":   []interface{}{1, 2},
+		"images":  []interface{}{"https://example.com/a.png"},
+		"options": map[string]interface{}{"seed": float64(1)},
+		"prompt":  `a "quoted" corgi`,
+		"text":    "plain",
+		"other":   []interface{}{float64(1), float64(2)},
+	}, coerced)
+
+	_, err = util.CoerceInputs(map[string]interface{}{"prompt": []interface{}{"a", "b"}}, schema)
+	assert.Error(t, err)
+
+	// Values that look like arrays must be valid JSON
+	_, err = util.CoerceInputs(map[string]interface{}{"sizes": "[1,2"}, schema)
+	assert.ErrorContains(t, err, "failed to unmarshal array")
+}
+
+func TestReadInputFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "prompt.txt"), []byte("a corgi"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "inputs.yaml"), []byte(`
+prompt: "@prompt.txt"
+author: "@corgilover"
+num_outputs: "2"
+sizes: [512, 768]
+options:
+  seed: 42
+`), 0o644))
+
+	options := util.InputOptions{InlineText: true}
+	doc, err := util.ReadInputFile(context.Background(), nil, filepath.Join(dir, "inputs.yaml"), options)
+	assert.NoError(t, err)
+
+	schema := &openapi3.Schema{
+		Type: &openapi3.Types{"object"},
+		Properties: openapi3.Schemas{
+			"num_outputs": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}}},
+			"sizes": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:  &openapi3.Types{"array"},
+				Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}}},
+			}},
+		},
+	}
+
+	inputs, err := util.ParseInputsWithOptions(context.Background(), nil, []string{"num_outputs=4"}, "", util.InputOptions{Separator: "="})
+	assert.NoError(t, err)
+
+	coerced, err := util.CoerceInputs(util.MergeInputs(doc, inputs), schema)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"prompt":      "a corgi",
+		"author":      "@corgilover",
+		"num_outputs": 4,
+		"sizes":       []interface{}{512, 768},
+		"options":     map[string]interface{}{"seed": float64(42)},
+	}, coerced)
+
+	coerced, err = util.CoerceInputs(doc, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, coerced["num_outputs"])
+
+	_, err = util.ParseInputJSON(context.Background(), nil, `["not", "an", "object"]`, options)
+	assert.Error(t, err)
+
+	doc, err = util.LoadInputDocument(context.Background(), nil, "", "", options)
+	assert.NoError(t, err)
+	assert.Nil(t, doc)
+}
+
+func TestParseInputsFromPredictions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions/succeeded":
+			fmt.Fprint(w, `{"id": "succeeded", "status": "succeeded", "output": ["https://example.com/a.png", "https://example.com/b.png"], "metrics": {"predict_time": 1.5}}`)
+		case "/predictions/failed":
+			fmt.Fprint(w, `{"id": "failed", "status": "failed", "error": "out of memory"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer mockServer.Close()
+
+	r8, err := replicate.NewClient(
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithToken("test-token"),
+	)
+	assert.NoError(t, err)
+
+	stdin := `{"id": "abc", "status": "succeeded", "output": ["https://example.com/c.png"]}`
+	inputs, err := util.ParseInputsWithOptions(context.Background(), r8, []string{
+		"image={{output[0]}}",
+		"images={{.output}}",
+		"caption=from {{$.id}}",
+		"first=pred:succeeded#$.output[0]",
+		"all=pred:succeeded",
+		"time=pred:succeeded#metrics.predict_time",
+	}, stdin, util.InputOptions{Separator: "="})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"image":   "https://example.com/c.png",
+		"images":  json.RawMessage(`["https://example.com/c.png"]`),
+		"caption": "from abc",
+		"first":   "https://example.com/a.png",
+		"all":     json.RawMessage(`["https://example.com/a.png","https://example.com/b.png"]`),
+		"time":    json.RawMessage(`1.5`),
+	}, inputs)
+
+	// Output piped on its own is treated as a prediction's output
+	inputs, err = util.ParseInputsWithOptions(context.Background(), r8, []string{"image={{output[0]}}"}, `["https://example.com/d.png"]`, util.InputOptions{Separator: "="})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/d.png", inputs["image"])
+
+	_, err = util.ParseInputsWithOptions(context.Background(), r8, []string{"image=pred:failed"}, "", util.InputOptions{Separator: "="})
+	assert.ErrorContains(t, err, "prediction failed didn't succeed")
+
+	// Without an API client, as with a local Cog server, references can't be resolved
+	_, err = util.ParseInputsWithOptions(context.Background(), nil, []string{"image=pred:succeeded"}, "", util.InputOptions{Separator: "="})
+	assert.ErrorContains(t, err, "pred: references need the Replicate API")
+	assert.Equal(t, util.KindInvalidInput, util.Kind(err))
+}
+
+func TestErrorKind(t *testing.T) {
+	failed := &replicate.Prediction{ID: "p1", Status: replicate.Failed, Error: "boom"}
+	canceled := &replicate.Prediction{ID: "p2", Status: replicate.Canceled}
+
+	testCases := []struct {
+		name     string
+		err      error
+		kind     util.ErrorKind
+		exitCode int
+	}{
+		{"plain", fmt.Errorf("oops"), util.KindError, 1},
+		{"invalid input", util.InvalidInputf("invalid model specified: %s", "x"), util.KindInvalidInput, 2},
+		{"unauthorized", fmt.Errorf("failed: %w", &replicate.APIError{Status: 401}), util.KindAuth, 3},
+		{"forbidden", &replicate.APIError{Status: 403}, util.KindAuth, 3},
+		{"not found", fmt.Errorf("failed: %w", &replicate.APIError{Status: 404}), util.KindNotFound, 4},
+		{"unprocessable", &replicate.APIError{Status: 422}, util.KindInvalidInput, 2},
+		{"server error", &replicate.APIError{Status: 500}, util.KindError, 1},
+		{"failed prediction", util.CheckPrediction(failed), util.KindPredictionFailed, 5},
+		{"canceled prediction", util.CheckPrediction(canceled), util.KindPredictionCanceled, 6},
+		{"network", fmt.Errorf("failed to make request: %w", &url.Error{Op: "Get", URL: "http://x", Err: fmt.Errorf("connection refused")}), util.KindNetwork, 7},
+		{"timeout", util.TimedOutf("prediction %s didn't finish within %s", "p1", time.Minute), util.KindTimeout, 8},
+		{"deadline", fmt.Errorf("failed to wait for prediction: %w", context.DeadlineExceeded), util.KindTimeout, 8},
+		{"interrupted", fmt.Errorf("failed to get model: %w", &url.Error{Op: "Get", URL: "http://x", Err: context.Canceled}), util.KindInterrupted, 130},
+		{"network input", util.InvalidInput(&url.Error{Op: "Post", URL: "http://x/files", Err: fmt.Errorf("connection refused")}), util.KindNetwork, 7},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.kind, util.Kind(tc.err))
+			assert.Equal(t, tc.exitCode, util.Kind(tc.err).ExitCode())
+		})
+	}
+
+	assert.NoError(t, util.CheckPrediction(&replicate.Prediction{Status: replicate.Succeeded}))
+	assert.EqualError(t, util.CheckPrediction(failed), "prediction p1 failed: boom")
+	assert.EqualError(t, util.CheckPrediction(canceled), "prediction p2 was canceled")
+}
+
+func TestNewErrorEnvelope(t *testing.T) {
+	err := fmt.Errorf("failed to get model: %w", &replicate.APIError{Status: 404, Title: "Not Found", Detail: "Not found."})
+
+	b, jsonErr := json.Marshal(util.NewErrorEnvelope(err))
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"error": {"kind": "not_found", "message": "failed to get model: Not Found: Not found.", "exit_code": 4, "status": 404, "title": "Not Found", "detail": "Not found."}}`, string(b))
+
+	envelope := util.NewErrorEnvelope(util.CheckPrediction(&replicate.Prediction{ID: "p1", Status: replicate.Failed}))
+	assert.Equal(t, "p1", envelope.Error.PredictionID)
+	assert.Equal(t, util.KindPredictionFailed, envelope.Error.Kind)
+}
+
+func TestNotifyInterrupt(t *testing.T) {
+	ctx, stop := util.NotifyInterrupt(context.Background())
+	defer stop()
+
+	assert.NoError(t, ctx.Err())
+
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, p.Signal(os.Interrupt))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context wasn't canceled by interrupt")
+	}
+	assert.Equal(t, util.KindInterrupted, util.Kind(ctx.Err()))
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, util.Stats{}, util.Summarize(nil))
+
+	stats := util.Summarize([]float64{4, 1, 3, 2, 10})
+	assert.Equal(t, 5, stats.Count)
+	assert.Equal(t, 20.0, stats.Total)
+	assert.Equal(t, 4.0, stats.Mean)
+	assert.Equal(t, 1.0, stats.Min)
+	assert.Equal(t, 10.0, stats.Max)
+	assert.Equal(t, 3.0, stats.P50)
+	assert.InDelta(t, 7.6, stats.P90, 1e-9)
+	assert.InDelta(t, 9.76, stats.P99, 1e-9)
+
+	assert.Equal(t, 2.0, util.Percentile([]float64{2}, 99))
+	assert.Equal(t, 1.5, util.Percentile([]float64{1, 2}, 50))
+}
@@ -0,0 +1,118 @@
+package util
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// WebhookHeaders are the headers used to sign a webhook request
+var WebhookHeaders = []string{"webhook-id", "webhook-timestamp", "webhook-signature"}
+
+// ParseWebhook returns a webhook for a URL and a list of events,
+// like "start,completed". It returns nil if the URL is empty.
+func ParseWebhook(rawURL string, events []string) (*replicate.Webhook, error) {
+	if rawURL == "" {
+		if len(events) > 0 {
+			return nil, fmt.Errorf("webhook events specified without a webhook URL")
+		}
+		return nil, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL: %s", rawURL)
+	}
+
+	webhook := &replicate.Webhook{URL: rawURL}
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if event == "" {
+			continue
+		}
+
+		valid := false
+		for _, e := range replicate.WebhookEventAll {
+			if string(e) == event {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid webhook event %q, must be one of %s", event, webhookEventNames())
+		}
+
+		webhook.Events = append(webhook.Events, replicate.WebhookEventType(event))
+	}
+
+	return webhook, nil
+}
+
+func webhookEventNames() string {
+	names := make([]string, len(replicate.WebhookEventAll))
+	for i, e := range replicate.WebhookEventAll {
+		names[i] = string(e)
+	}
+	return strings.Join(names, ", ")
+}
+
+// SignWebhook returns the signature header for a webhook request
+// with the given ID, timestamp and body, signed with a "whsec_" secret
+func SignWebhook(id string, timestamp string, body []byte, secret string) (string, error) {
+	_, key, ok := strings.Cut(secret, "_")
+	if !ok {
+		return "", fmt.Errorf("invalid webhook secret, expected a key like whsec_...")
+	}
+
+	secretBytes, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode webhook secret: %w", err)
+	}
+
+	h := hmac.New(sha256.New, secretBytes)
+	fmt.Fprintf(h, "%s.%s.%s", id, timestamp, body)
+
+	return "v1," + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyWebhook checks the signature of a webhook request's headers and body.
+// If tolerance isn't zero, it also checks that the request was sent within
+// that long of now, to guard against replayed requests.
+func VerifyWebhook(header http.Header, body []byte, secret string, tolerance time.Duration) error {
+	req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+
+	valid, err := replicate.ValidateWebhookRequest(req, replicate.WebhookSigningSecret{Key: secret})
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("webhook signature doesn't match")
+	}
+
+	if tolerance > 0 {
+		seconds, err := strconv.ParseInt(header.Get("webhook-timestamp"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid webhook timestamp: %s", header.Get("webhook-timestamp"))
+		}
+
+		age := time.Since(time.Unix(seconds, 0))
+		if age > tolerance || age < -tolerance {
+			return fmt.Errorf("webhook timestamp is %s from now, outside the tolerance of %s", age.Round(time.Second), tolerance)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,427 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// StepStatus is the status of a step in a run
+type StepStatus string
+
+const (
+	StepPending   StepStatus = "pending"
+	StepRunning   StepStatus = "running"
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+	StepSkipped   StepStatus = "skipped"
+)
+
+// StepFunc creates a prediction for a step with evaluated inputs and waits for it to finish
+type StepFunc func(ctx context.Context, step *Step, inputs map[string]interface{}) (*replicate.Prediction, error)
+
+// StepResult is the result of running a step
+type StepResult struct {
+	ID     string     `json:"id"`
+	Status StepStatus `json:"status"`
+
+	// Attempts is the number of predictions created, including retries
+	Attempts int `json:"attempts"`
+
+	// Predictions are the final predictions, one for each item of a for_each step
+	Predictions []*replicate.Prediction `json:"predictions,omitempty"`
+
+	Error       string     `json:"error,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	forEach bool
+}
+
+// Manifest records a run of a workflow
+type Manifest struct {
+	Workflow    string                 `json:"workflow,omitempty"`
+	Status      StepStatus             `json:"status"`
+	Inputs      map[string]interface{} `json:"inputs,omitempty"`
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt time.Time              `json:"completed_at"`
+	Steps       []*StepResult          `json:"steps"`
+}
+
+// Runner runs the steps of a workflow, starting each step once the steps it needs have finished
+type Runner struct {
+	Workflow *Workflow
+	Run      StepFunc
+
+	// Concurrency is the most predictions that run at once, or 0 for no limit
+	Concurrency int
+
+	// RetryDelay is how long to wait before retrying a failed prediction
+	RetryDelay time.Duration
+
+	// OnUpdate is called with a copy of a step's result whenever it changes.
+	// It can be called from several goroutines at once.
+	OnUpdate func(result StepResult)
+
+	mu  sync.Mutex
+	sem chan struct{}
+}
+
+// Execute runs the workflow with inputs that override its default inputs.
+// Once a step fails, steps that haven't started are skipped.
+// The manifest is returned even if the run fails.
+func (r *Runner) Execute(ctx context.Context, inputs map[string]interface{}) (*Manifest, error) {
+	steps, err := r.Workflow.Order()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Concurrency > 0 {
+		r.sem = make(chan struct{}, r.Concurrency)
+	}
+
+	merged := map[string]interface{}{}
+	for k, v := range r.Workflow.Inputs {
+		merged[k] = v
+	}
+	for k, v := range inputs {
+		merged[k] = v
+	}
+
+	manifest := &Manifest{
+		Workflow:  r.Workflow.Name,
+		Inputs:    merged,
+		StartedAt: time.Now(),
+	}
+
+	results := map[string]*StepResult{}
+	for _, step := range steps {
+		result := &StepResult{ID: step.ID, Status: StepPending, forEach: step.ForEach != ""}
+		results[step.ID] = result
+		manifest.Steps = append(manifest.Steps, result)
+		r.update(result)
+	}
+
+	pending := append([]*Step{}, steps...)
+	done := make(chan *Step)
+	running := 0
+	var failure error
+
+	for len(pending) > 0 || running > 0 {
+		remaining := pending[:0]
+		for _, step := range pending {
+			result := results[step.ID]
+
+			ready, reason := true, ""
+			for _, dep := range step.Dependencies() {
+				switch r.status(results[dep]) {
+				case StepFailed:
+					reason = fmt.Sprintf("step %s failed", dep)
+				case StepSkipped:
+					reason = fmt.Sprintf("step %s was skipped", dep)
+				case StepSucceeded:
+					continue
+				default:
+					ready = false
+				}
+			}
+			if failure != nil && reason == "" {
+				reason = "the workflow failed"
+			}
+
+			switch {
+			case reason != "":
+				r.finish(result, StepSkipped, "skipped because "+reason)
+			case ready:
+				r.mu.Lock()
+				scope := map[string]interface{}{
+					"inputs": merged,
+					"steps":  stepScopes(results),
+				}
+				r.mu.Unlock()
+
+				r.start(result)
+				running++
+				go func(step *Step) {
+					r.runStep(ctx, step, scope, result)
+					done <- step
+				}(step)
+			default:
+				remaining = append(remaining, step)
+			}
+		}
+		pending = remaining
+
+		if running == 0 {
+			continue
+		}
+
+		step := <-done
+		running--
+		if result := results[step.ID]; result.Status == StepFailed && failure == nil {
+			failure = fmt.Errorf("step %s failed: %s", step.ID, result.Error)
+		}
+	}
+
+	manifest.CompletedAt = time.Now()
+	manifest.Status = StepSucceeded
+	if failure != nil {
+		manifest.Status = StepFailed
+	}
+
+	return manifest, failure
+}
+
+// runStep runs the predictions for a step, retrying failed predictions
+func (r *Runner) runStep(ctx context.Context, step *Step, scope map[string]interface{}, result *StepResult) {
+	if step.If != "" {
+		value, err := evaluateExpression(step.If, scope)
+		if err != nil {
+			r.finish(result, StepFailed, err.Error())
+			return
+		}
+		if !truthy(value) {
+			r.finish(result, StepSkipped, "skipped because its condition is false")
+			return
+		}
+	}
+
+	items := []interface{}{nil}
+	if step.ForEach != "" {
+		value, err := evaluateExpression(step.ForEach, scope)
+		if err != nil {
+			r.finish(result, StepFailed, err.Error())
+			return
+		}
+
+		list, ok := value.([]interface{})
+		if !ok {
+			r.finish(result, StepFailed, fmt.Sprintf("for_each must be a list, got %T", value))
+			return
+		}
+		items = list
+	}
+
+	r.mu.Lock()
+	result.Predictions = make([]*replicate.Prediction, len(items))
+	r.mu.Unlock()
+
+	errs := make([]error, len(items))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		itemScope := make(map[string]interface{}, len(scope)+2)
+		for k, v := range scope {
+			itemScope[k] = v
+		}
+		if step.ForEach != "" {
+			itemScope["item"] = item
+			itemScope["index"] = i
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = r.runPrediction(ctx, step, itemScope, result, i)
+		}(i)
+	}
+	wg.Wait()
+
+	messages := []string{}
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if step.ForEach != "" {
+			messages = append(messages, fmt.Sprintf("item %d: %s", i, err))
+		} else {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	if len(messages) > 0 {
+		r.finish(result, StepFailed, strings.Join(messages, "; "))
+		return
+	}
+
+	r.finish(result, StepSucceeded, "")
+}
+
+// runPrediction creates the prediction for one item of a step
+func (r *Runner) runPrediction(ctx context.Context, step *Step, scope map[string]interface{}, result *StepResult, index int) error {
+	evaluated, err := Evaluate(step.Inputs, scope)
+	if err != nil {
+		return err
+	}
+	inputs, _ := evaluated.(map[string]interface{})
+	if inputs == nil {
+		inputs = map[string]interface{}{}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= step.Retries; attempt++ {
+		if attempt > 0 && r.RetryDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.RetryDelay):
+			}
+		}
+
+		if err := r.acquire(ctx); err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		result.Attempts++
+		r.mu.Unlock()
+
+		prediction, err := r.Run(ctx, step, inputs)
+		r.release()
+
+		if prediction != nil {
+			r.mu.Lock()
+			result.Predictions[index] = prediction
+			r.mu.Unlock()
+			r.update(result)
+		}
+
+		switch {
+		case err != nil:
+			lastErr = err
+		case prediction.Status != replicate.Succeeded:
+			lastErr = fmt.Errorf("prediction %s %s", prediction.ID, prediction.Status)
+			if prediction.Error != nil {
+				lastErr = fmt.Errorf("prediction %s %s: %v", prediction.ID, prediction.Status, prediction.Error)
+			}
+		default:
+			return nil
+		}
+
+		if ctx.Err() != nil || errors.Is(lastErr, context.Canceled) {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+func (r *Runner) acquire(ctx context.Context) error {
+	if r.sem == nil {
+		return nil
+	}
+
+	select {
+	case r.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) release() {
+	if r.sem != nil {
+		<-r.sem
+	}
+}
+
+func (r *Runner) status(result *StepResult) StepStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return result.Status
+}
+
+func (r *Runner) start(result *StepResult) {
+	now := time.Now()
+
+	r.mu.Lock()
+	result.Status = StepRunning
+	result.StartedAt = &now
+	r.mu.Unlock()
+
+	r.update(result)
+}
+
+func (r *Runner) finish(result *StepResult, status StepStatus, message string) {
+	now := time.Now()
+
+	r.mu.Lock()
+	result.Status = status
+	result.Error = message
+	result.CompletedAt = &now
+	r.mu.Unlock()
+
+	r.update(result)
+}
+
+// update calls OnUpdate with a copy of the result
+func (r *Runner) update(result *StepResult) {
+	if r.OnUpdate == nil {
+		return
+	}
+
+	r.mu.Lock()
+	copied := *result
+	copied.Predictions = append([]*replicate.Prediction{}, result.Predictions...)
+	r.mu.Unlock()
+
+	r.OnUpdate(copied)
+}
+
+// stepScopes returns the values that templates refer to as steps.<id> for succeeded steps.
+// A step has the fields of its prediction, like output and metrics.
+// A for_each step has a list of outputs and a list of predictions.
+func stepScopes(results map[string]*StepResult) map[string]interface{} {
+	scopes := map[string]interface{}{}
+	for id, result := range results {
+		if result.Status != StepSucceeded {
+			continue
+		}
+
+		predictions := make([]interface{}, len(result.Predictions))
+		outputs := make([]interface{}, len(result.Predictions))
+		for i, prediction := range result.Predictions {
+			predictions[i] = toJSONValue(prediction)
+			if p, ok := predictions[i].(map[string]interface{}); ok {
+				outputs[i] = p["output"]
+			}
+		}
+
+		switch {
+		case result.forEach:
+			scopes[id] = map[string]interface{}{
+				"status":      string(result.Status),
+				"output":      outputs,
+				"predictions": predictions,
+			}
+		case len(predictions) == 1:
+			scope, _ := predictions[0].(map[string]interface{})
+			if scope == nil {
+				scope = map[string]interface{}{}
+			}
+			scope["status"] = string(result.Status)
+			scopes[id] = scope
+		}
+	}
+	return scopes
+}
+
+// toJSONValue converts a value to the maps, lists and values decoded from its JSON,
+// so expressions use the same field names as the API
+func toJSONValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	return decoded
+}
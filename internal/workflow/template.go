@@ -0,0 +1,178 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// language evaluates expressions like steps.generate.output[0] or inputs.count > 2,
+// JSON paths like $.steps.generate.output[*], and len() of lists, maps and strings
+var language = gval.NewLanguage(
+	gval.Full(),
+	jsonpath.Language(),
+	gval.Function("len", func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case []interface{}:
+			return len(v), nil
+		case map[string]interface{}:
+			return len(v), nil
+		case string:
+			return len(v), nil
+		}
+		return nil, fmt.Errorf("len of %T isn't supported", value)
+	}),
+)
+
+var (
+	templatePattern      = regexp.MustCompile(`{{(.*?)}}`)
+	stepReferencePattern = regexp.MustCompile(`\bsteps\.([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// Evaluate replaces the templates in strings within a value.
+// A string that's only a template, like "{{ steps.a.output }}",
+// is replaced with the value of its expression, which keeps its type.
+func Evaluate(value interface{}, scope map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return evaluateString(v, scope)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			evaluated, err := Evaluate(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = evaluated
+		}
+		return values, nil
+	case map[string]interface{}:
+		values := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			evaluated, err := Evaluate(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			values[k] = evaluated
+		}
+		return values, nil
+	}
+
+	return value, nil
+}
+
+func evaluateString(s string, scope map[string]interface{}) (interface{}, error) {
+	matches := templatePattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return evaluateExpression(s[matches[0][2]:matches[0][3]], scope)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		b.WriteString(s[last:match[0]])
+
+		value, err := evaluateExpression(s[match[2]:match[3]], scope)
+		if err != nil {
+			return nil, err
+		}
+
+		switch value := value.(type) {
+		case string:
+			b.WriteString(value)
+		case nil:
+		default:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal value of %s: %w", s[match[0]:match[1]], err)
+			}
+			b.Write(data)
+		}
+
+		last = match[1]
+	}
+	b.WriteString(s[last:])
+
+	return b.String(), nil
+}
+
+// evaluateExpression evaluates an expression, which can also be written as a template
+func evaluateExpression(expr string, scope map[string]interface{}) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{{") {
+		return evaluateString(expr, scope)
+	}
+
+	value, err := language.Evaluate(expr, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", expr, err)
+	}
+
+	return value, nil
+}
+
+// truthy reports whether a value counts as true for a condition
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != "" && !strings.EqualFold(v, "false")
+	case float64:
+		return v != 0
+	case int:
+		return v != 0
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+
+	return true
+}
+
+// expressionReferences returns the IDs of the steps an expression refers to
+func expressionReferences(expr string) []string {
+	ids := []string{}
+	for _, match := range stepReferencePattern.FindAllStringSubmatch(expr, -1) {
+		ids = append(ids, match[1])
+	}
+	return ids
+}
+
+// templateReferences returns the IDs of the steps the templates within a value refer to
+func templateReferences(value interface{}) []string {
+	ids := []string{}
+	switch v := value.(type) {
+	case string:
+		for _, match := range templatePattern.FindAllStringSubmatch(v, -1) {
+			ids = append(ids, expressionReferences(match[1])...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			ids = append(ids, templateReferences(item)...)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			ids = append(ids, templateReferences(v[k])...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,202 @@
+// Package workflow runs workflows of predictions that are defined in YAML.
+//
+// Each step runs a model or deployment. Step inputs can refer to the
+// workflow's inputs and to earlier steps with templates like
+// {{ steps.generate.output[0] }}, which also determine the order of steps.
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow is a set of steps that run predictions
+type Workflow struct {
+	Name string `yaml:"name" json:"name,omitempty"`
+
+	// Inputs are the default values of the workflow's inputs,
+	// which steps refer to like {{ inputs.prompt }}
+	Inputs map[string]interface{} `yaml:"inputs" json:"inputs,omitempty"`
+
+	Steps []*Step `yaml:"steps" json:"steps"`
+
+	// Dir is the directory of the workflow file, for resolving @file inputs
+	Dir string `yaml:"-" json:"-"`
+}
+
+// Step runs a prediction with a model or deployment
+type Step struct {
+	ID string `yaml:"id" json:"id"`
+
+	// Model is a model identifier, like owner/name or owner/name:version
+	Model string `yaml:"model" json:"model,omitempty"`
+
+	// Deployment is a deployment identifier, like owner/name
+	Deployment string `yaml:"deployment" json:"deployment,omitempty"`
+
+	// Inputs are the prediction's inputs, which can contain templates
+	Inputs map[string]interface{} `yaml:"inputs" json:"inputs,omitempty"`
+
+	// Needs lists steps that must finish first,
+	// in addition to the steps referred to by templates
+	Needs []string `yaml:"needs" json:"needs,omitempty"`
+
+	// If is an expression that skips the step when it's false,
+	// like steps.classify.output == "cat"
+	If string `yaml:"if" json:"if,omitempty"`
+
+	// ForEach is an expression for a list.
+	// A prediction is created for each item, which inputs refer to as {{ item }}.
+	ForEach string `yaml:"for_each" json:"for_each,omitempty"`
+
+	// Retries is how many more times a failed prediction is tried
+	Retries int `yaml:"retries" json:"retries,omitempty"`
+}
+
+var stepIDPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Load reads a workflow from a YAML file
+func Load(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow: %w", err)
+	}
+
+	w, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	w.Dir = filepath.Dir(path)
+
+	return w, nil
+}
+
+// Parse reads a workflow from YAML and validates it
+func Parse(data []byte) (*Workflow, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	w := &Workflow{}
+	if err := decoder.Decode(w); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Validate checks that the steps are well formed and don't depend on each other in a cycle
+func (w *Workflow) Validate() error {
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("workflow has no steps")
+	}
+
+	ids := map[string]bool{}
+	for i, step := range w.Steps {
+		if step == nil {
+			return fmt.Errorf("step %d is empty", i+1)
+		}
+		if !stepIDPattern.MatchString(step.ID) {
+			return fmt.Errorf("step %d has an invalid id %q: ids must be letters, digits and underscores", i+1, step.ID)
+		}
+		if ids[step.ID] {
+			return fmt.Errorf("step %s is defined more than once", step.ID)
+		}
+		ids[step.ID] = true
+
+		if (step.Model == "") == (step.Deployment == "") {
+			return fmt.Errorf("step %s must have either a model or a deployment", step.ID)
+		}
+		if step.Retries < 0 {
+			return fmt.Errorf("step %s has negative retries", step.ID)
+		}
+	}
+
+	for _, step := range w.Steps {
+		for _, dep := range step.Dependencies() {
+			if dep == step.ID {
+				return fmt.Errorf("step %s depends on itself", step.ID)
+			}
+			if !ids[dep] {
+				return fmt.Errorf("step %s depends on unknown step %s", step.ID, dep)
+			}
+		}
+	}
+
+	_, err := w.Order()
+	return err
+}
+
+// Order returns the steps sorted so that each step comes after its dependencies.
+// Steps keep the order they're defined in where possible.
+func (w *Workflow) Order() ([]*Step, error) {
+	ordered := make([]*Step, 0, len(w.Steps))
+	done := map[string]bool{}
+
+	for len(ordered) < len(w.Steps) {
+		progress := false
+		for _, step := range w.Steps {
+			if done[step.ID] || !dependenciesDone(step, done) {
+				continue
+			}
+			ordered = append(ordered, step)
+			done[step.ID] = true
+			progress = true
+		}
+
+		if !progress {
+			for _, step := range w.Steps {
+				if !done[step.ID] {
+					return nil, fmt.Errorf("step %s is part of a dependency cycle", step.ID)
+				}
+			}
+		}
+	}
+
+	return ordered, nil
+}
+
+func dependenciesDone(step *Step, done map[string]bool) bool {
+	for _, dep := range step.Dependencies() {
+		if !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// Dependencies returns the IDs of the steps this step needs,
+// including the steps its templates and expressions refer to
+func (s *Step) Dependencies() []string {
+	deps := []string{}
+	seen := map[string]bool{}
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			deps = append(deps, id)
+		}
+	}
+
+	for _, id := range s.Needs {
+		add(id)
+	}
+	for _, id := range expressionReferences(s.If) {
+		add(id)
+	}
+	for _, id := range expressionReferences(s.ForEach) {
+		add(id)
+	}
+	for _, id := range templateReferences(s.Inputs) {
+		add(id)
+	}
+
+	return deps
+}
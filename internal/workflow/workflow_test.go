@@ -0,0 +1,170 @@
+package workflow_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/replicate/replicate-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/cli/internal/workflow"
+)
+
+const flow = `
+name: corgis
+inputs:
+  prompt: a corgi
+  upscale: true
+steps:
+  - id: generate
+    model: stability-ai/sdxl
+    inputs:
+      prompt: "{{ inputs.prompt }}, studio photo"
+      num_outputs: 2
+  - id: upscale
+    model: nightmareai/real-esrgan
+    for_each: steps.generate.output
+    if: inputs.upscale
+    retries: 1
+    inputs:
+      image: "{{ item }}"
+  - id: caption
+    deployment: acme/captioner
+    inputs:
+      images: "{{ steps.upscale.output }}"
+      count: "{{ len(steps.upscale.output) }}"
+`
+
+func TestParse(t *testing.T) {
+	w, err := workflow.Parse([]byte(flow))
+	assert.NoError(t, err)
+	assert.Equal(t, "corgis", w.Name)
+	assert.Len(t, w.Steps, 3)
+	assert.Equal(t, []string{"generate"}, w.Steps[1].Dependencies())
+	assert.Equal(t, []string{"upscale"}, w.Steps[2].Dependencies())
+
+	for name, data := range map[string]string{
+		"no steps":        `name: empty`,
+		"unknown field":   "steps:\n  - id: a\n    model: a/b\n    input: {}",
+		"invalid id":      "steps:\n  - id: a-b\n    model: a/b",
+		"duplicate id":    "steps:\n  - id: a\n    model: a/b\n  - id: a\n    model: a/b",
+		"model and dep":   "steps:\n  - id: a\n    model: a/b\n    deployment: a/b",
+		"unknown step":    "steps:\n  - id: a\n    model: a/b\n    needs: [b]",
+		"dependency loop": "steps:\n  - id: a\n    model: a/b\n    needs: [b]\n  - id: b\n    model: a/b\n    inputs:\n      x: '{{ steps.a.output }}'",
+	} {
+		_, err := workflow.Parse([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	scope := map[string]interface{}{
+		"inputs": map[string]interface{}{"prompt": "a corgi", "seed": 42},
+		"steps": map[string]interface{}{
+			"generate": map[string]interface{}{"output": []interface{}{"a.png", "b.png"}},
+		},
+	}
+
+	value, err := workflow.Evaluate(map[string]interface{}{
+		"prompt": "{{ inputs.prompt }} in {{ steps.generate.output }}",
+		"seed":   "{{ inputs.seed }}",
+		"images": []interface{}{"{{ steps.generate.output[1] }}", "c.png"},
+		"all":    "{{ $.steps.generate.output[*] }}",
+		"plain":  3,
+	}, scope)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"prompt": `a corgi in ["a.png","b.png"]`,
+		"seed":   42,
+		"images": []interface{}{"b.png", "c.png"},
+		"all":    []interface{}{"a.png", "b.png"},
+		"plain":  3,
+	}, value)
+
+	_, err = workflow.Evaluate("{{ steps.missing.output }}", scope)
+	assert.Error(t, err)
+}
+
+func TestRunner(t *testing.T) {
+	w, err := workflow.Parse([]byte(flow))
+	assert.NoError(t, err)
+
+	var mu sync.Mutex
+	calls := map[string][]map[string]interface{}{}
+	failures := 1
+
+	runner := &workflow.Runner{
+		Workflow: w,
+		Run: func(_ context.Context, step *workflow.Step, inputs map[string]interface{}) (*replicate.Prediction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls[step.ID] = append(calls[step.ID], inputs)
+			id := fmt.Sprintf("%s-%d", step.ID, len(calls[step.ID]))
+
+			switch step.ID {
+			case "generate":
+				return &replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: []interface{}{"a.png", "b.png"}}, nil
+			case "upscale":
+				if inputs["image"] == "b.png" && failures > 0 {
+					failures--
+					return &replicate.Prediction{ID: id, Status: replicate.Failed}, nil
+				}
+				return &replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: "big-" + inputs["image"].(string)}, nil
+			}
+			return &replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: "two corgis"}, nil
+		},
+	}
+
+	manifest, err := runner.Execute(context.Background(), map[string]interface{}{"prompt": "a cat"})
+	assert.NoError(t, err)
+	assert.Equal(t, workflow.StepSucceeded, manifest.Status)
+
+	assert.Equal(t, "a cat, studio photo", calls["generate"][0]["prompt"])
+	assert.Len(t, calls["upscale"], 3)
+	assert.Equal(t, map[string]interface{}{
+		"images": []interface{}{"big-a.png", "big-b.png"},
+		"count":  2,
+	}, calls["caption"][0])
+
+	upscale := manifest.Steps[1]
+	assert.Equal(t, "upscale", upscale.ID)
+	assert.Equal(t, 3, upscale.Attempts)
+	assert.Len(t, upscale.Predictions, 2)
+
+	// Steps after a skipped step are skipped too
+	calls = map[string][]map[string]interface{}{}
+	manifest, err = runner.Execute(context.Background(), map[string]interface{}{"upscale": false})
+	assert.NoError(t, err)
+	assert.Equal(t, workflow.StepSkipped, manifest.Steps[1].Status)
+	assert.Equal(t, workflow.StepSkipped, manifest.Steps[2].Status)
+	assert.Empty(t, calls["caption"])
+}
+
+func TestRunnerFailure(t *testing.T) {
+	w, err := workflow.Parse([]byte(`
+steps:
+  - id: a
+    model: a/a
+  - id: b
+    model: b/b
+    inputs:
+      x: "{{ steps.a.output }}"
+`))
+	assert.NoError(t, err)
+
+	runner := &workflow.Runner{
+		Workflow: w,
+		Run: func(_ context.Context, step *workflow.Step, _ map[string]interface{}) (*replicate.Prediction, error) {
+			return nil, fmt.Errorf("model %s not found", step.Model)
+		},
+	}
+
+	manifest, err := runner.Execute(context.Background(), nil)
+	assert.ErrorContains(t, err, "step a failed: model a/a not found")
+	assert.Equal(t, workflow.StepFailed, manifest.Status)
+	assert.Equal(t, workflow.StepFailed, manifest.Steps[0].Status)
+	assert.Equal(t, workflow.StepSkipped, manifest.Steps[1].Status)
+}